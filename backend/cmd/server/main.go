@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/AntVerkh/test-management-system/internal/config"
@@ -11,6 +13,8 @@ import (
 	"github.com/AntVerkh/test-management-system/internal/service"
 	"github.com/AntVerkh/test-management-system/pkg/auth"
 	"github.com/AntVerkh/test-management-system/pkg/database"
+	"github.com/AntVerkh/test-management-system/pkg/mailer"
+	"github.com/AntVerkh/test-management-system/pkg/oidc"
 	"github.com/AntVerkh/test-management-system/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
@@ -30,39 +34,165 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
-	// Initialize storage (remove unused variable warning by using it)
+	// Initialize storage
 	fileStorage := storage.NewLocalFileStorage(cfg.FileStoragePath)
-	_ = fileStorage // Use the variable to avoid "unused variable" error
+
+	// Initialize mailer. Logged mail exposes the sign-in links in it, so the
+	// log driver has to be chosen explicitly and is refused in production.
+	var mail mailer.Mailer
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			log.Fatal("Failed to configure mailer: SMTP_HOST is required, or set MAIL_DRIVER=log in development")
+		}
+		mail, err = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+		if err != nil {
+			log.Fatal("Failed to configure mailer:", err)
+		}
+	case "log":
+		if cfg.Environment == "production" {
+			log.Fatal("Failed to configure mailer: MAIL_DRIVER=log is not allowed in production")
+		}
+		log.Println("Warning: mail is written to the log, including password reset and invitation links")
+		mail = mailer.NewLogMailer()
+	default:
+		log.Fatalf("Failed to configure mailer: unknown MAIL_DRIVER %q", cfg.MailDriver)
+	}
+
+	// Initialize single sign-on, which stays off without an issuer
+	var sso service.SSOConfig
+	if cfg.OIDC.IssuerURL != "" {
+		sso, err = ssoConfig(cfg.OIDC)
+		if err != nil {
+			log.Fatal("Failed to configure single sign-on:", err)
+		}
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
 	testPlanRepo := repository.NewTestPlanRepository(db)
 	testCaseRepo := repository.NewTestCaseRepository(db)
 	checklistRepo := repository.NewChecklistRepository(db)
 	testStrategyRepo := repository.NewTestStrategyRepository(db)
 	testRunRepo := repository.NewTestRunRepository(db)
+	historyRepo := repository.NewHistoryRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	serviceAccountRepo := repository.NewServiceAccountRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	oidcRequestRepo := repository.NewOIDCAuthRequestRepository(db)
+	transactor := repository.NewTransactor(db)
 
 	// Initialize services
-	jwtService := auth.NewJWTService(cfg.JWTSecret)
-	authService := service.NewAuthService(userRepo, jwtService)
-	testPlanService := service.NewTestPlanService(testPlanRepo)
-	testCaseService := service.NewTestCaseService(testCaseRepo)
-	userService := service.NewUserService(userRepo)
-	exporter := domain.NewMarkdownExporter()
+	jwtService := auth.NewJWTService(cfg.JWTSecret, cfg.AccessTokenTTL)
+	mfaService := service.NewMFAService(mfaRepo, userRepo)
+	authService := service.NewAuthService(
+		userRepo,
+		sessionRepo,
+		userTokenRepo,
+		oidcRequestRepo,
+		jwtService,
+		mfaService,
+		mail,
+		cfg.RefreshTokenTTL,
+		cfg.AppURL,
+		sso,
+	)
+	historyService := service.NewHistoryService(historyRepo)
+	commentService := service.NewCommentService(commentRepo)
+	attachmentService := service.NewAttachmentService(
+		attachmentRepo,
+		testCaseRepo,
+		testRunRepo,
+		fileStorage,
+		cfg.MaxUploadSize,
+		cfg.AllowedMimeTypes,
+	)
+	projectService := service.NewProjectService(projectRepo, userRepo)
+	testPlanService := service.NewTestPlanService(testPlanRepo, testCaseRepo, checklistRepo, historyService, transactor)
+	testCaseService := service.NewTestCaseService(testCaseRepo, historyService, transactor)
+	checklistService := service.NewChecklistService(checklistRepo, historyService, transactor)
+	testStrategyService := service.NewTestStrategyService(testStrategyRepo, historyService, transactor)
+	testRunService := service.NewTestRunService(testRunRepo, testPlanRepo, testCaseRepo, historyService, transactor)
+	trashService := service.NewTrashService(trashRepo, historyService, fileStorage, cfg.TrashRetention, transactor)
+	userService := service.NewUserService(userRepo, sessionRepo)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo)
+	serviceAccountService := service.NewServiceAccountService(serviceAccountRepo)
+	invitationService := service.NewInvitationService(invitationRepo, userRepo, mail, cfg.AppURL)
+	exporters := domain.NewExporterRegistry()
+	exporters.Register(domain.ExportFormatInfo{
+		Format:    domain.ExportFormatMarkdown,
+		Name:      "Markdown",
+		MimeType:  "text/markdown; charset=utf-8",
+		Extension: "md",
+	}, domain.NewMarkdownExporter())
+	exporters.Register(domain.ExportFormatInfo{
+		Format:    domain.ExportFormatHTML,
+		Name:      "HTML",
+		MimeType:  "text/html; charset=utf-8",
+		Extension: "html",
+	}, domain.NewHTMLExporter())
+	exporters.Register(domain.ExportFormatInfo{
+		Format:    domain.ExportFormatPDF,
+		Name:      "PDF",
+		MimeType:  "application/pdf",
+		Extension: "pdf",
+	}, domain.NewPDFExporter(fileStorage))
+	exporters.Register(domain.ExportFormatInfo{
+		Format:      domain.ExportFormatJUnit,
+		Name:        "JUnit XML",
+		MimeType:    "application/xml; charset=utf-8",
+		Extension:   "xml",
+		EntityTypes: []string{domain.EntityTypeTestRun},
+	}, domain.NewJUnitExporter())
+	exporters.Register(domain.ExportFormatInfo{
+		Format:      domain.ExportFormatCSV,
+		Name:        "CSV",
+		MimeType:    "text/csv; charset=utf-8",
+		Extension:   "csv",
+		EntityTypes: []string{domain.EntityTypeTestCase, domain.EntityTypeChecklist, domain.EntityTypeTestRun},
+	}, domain.NewCSVExporter())
+	exporters.Register(domain.ExportFormatInfo{
+		Format:      domain.ExportFormatXLSX,
+		Name:        "Excel",
+		MimeType:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Extension:   "xlsx",
+		EntityTypes: []string{domain.EntityTypeTestCase, domain.EntityTypeChecklist, domain.EntityTypeTestRun},
+	}, domain.NewXLSXExporter())
 	exportService := service.NewExportService(
 		testPlanRepo,
 		testCaseRepo,
 		checklistRepo,
 		testStrategyRepo,
 		testRunRepo,
-		exporter,
+		historyRepo,
+		commentRepo,
+		exporters,
 	)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, userService)
-	testPlanHandler := handler.NewTestPlanHandler(testPlanService)
-	testCaseHandler := handler.NewTestCaseHandler(testCaseService)
-	exportHandler := handler.NewExportHandler(exportService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	testPlanHandler := handler.NewTestPlanHandler(testPlanService, projectService)
+	testCaseHandler := handler.NewTestCaseHandler(testCaseService, projectService, cfg.MaxUploadSize)
+	checklistHandler := handler.NewChecklistHandler(checklistService, projectService)
+	testStrategyHandler := handler.NewTestStrategyHandler(testStrategyService, projectService)
+	testRunHandler := handler.NewTestRunHandler(testRunService, testPlanService, projectService, cfg.MaxUploadSize)
+	historyHandler := handler.NewHistoryHandler(historyService, projectService)
+	commentHandler := handler.NewCommentHandler(commentService, projectService)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, projectService, cfg.MaxUploadSize)
+	exportHandler := handler.NewExportHandler(exportService, projectService)
+	trashHandler := handler.NewTrashHandler(trashService, projectService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountService, apiTokenService, projectService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	mfaHandler := handler.NewMFAHandler(mfaService, userService)
 
 	// Setup router
 	if cfg.Environment == "production" {
@@ -76,36 +206,153 @@ func main() {
 	public := router.Group("/api/v1")
 	{
 		public.POST("/auth/login", authHandler.Login)
+		public.POST("/auth/login/mfa", authHandler.CompleteMFALogin)
+		public.POST("/auth/login/mfa/enroll", authHandler.EnrollMFALogin)
+		public.POST("/auth/login/mfa/confirm", authHandler.ConfirmMFALogin)
+		public.GET("/auth/oidc", authHandler.GetSSOInfo)
+		public.POST("/auth/oidc/authorize", authHandler.StartSSOLogin)
+		public.POST("/auth/oidc/callback", authHandler.SSOCallback)
 		public.POST("/auth/register", authHandler.Register)
+		public.POST("/auth/refresh", authHandler.Refresh)
+		public.POST("/auth/verify-email", authHandler.VerifyEmail)
+		public.POST("/auth/resend-verification", authHandler.ResendVerification)
+		public.POST("/auth/forgot-password", authHandler.ForgotPassword)
+		public.POST("/auth/reset-password", authHandler.ResetPassword)
+		public.POST("/auth/invitations/accept", invitationHandler.AcceptInvitation)
 	}
 
-	// Protected routes
+	// Protected routes. API tokens need the admin scope to change data through
+	// them; reads and results below are for routes that need less.
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.AuthMiddleware(authService))
+	protected.Use(middleware.AuthMiddleware(authService, apiTokenService, domain.TokenScopeAdmin))
+
+	// Routes that only read data although they are not GET requests
+	reads := router.Group("/api/v1")
+	reads.Use(middleware.AuthMiddleware(authService, apiTokenService, domain.TokenScopeRead))
+
+	// Routes that record test results, open to tokens with the write-results scope
+	results := router.Group("/api/v1")
+	results.Use(middleware.AuthMiddleware(authService, apiTokenService, domain.TokenScopeWriteResults))
 	{
 		// User routes
 		protected.GET("/profile", authHandler.GetProfile)
+		protected.POST("/auth/logout", authHandler.Logout)
+		protected.POST("/auth/change-password", authHandler.ChangePassword)
+
+		// Two-factor authentication
+		protected.GET("/auth/mfa", mfaHandler.GetStatus)
+		protected.POST("/auth/mfa/enroll", mfaHandler.Enroll)
+		protected.POST("/auth/mfa/confirm", mfaHandler.Confirm)
+		protected.POST("/auth/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		protected.POST("/auth/mfa/disable", mfaHandler.Disable)
+
+		// API tokens
+		protected.GET("/tokens", apiTokenHandler.ListTokens)
+		protected.POST("/tokens", apiTokenHandler.CreateToken)
+		protected.DELETE("/tokens/:id", apiTokenHandler.RevokeToken)
+
+		// Projects
+		protected.GET("/projects", projectHandler.ListProjects)
+		protected.POST("/projects", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), projectHandler.CreateProject)
+		protected.GET("/projects/:id", projectHandler.GetProject)
+		protected.PUT("/projects/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), projectHandler.UpdateProject)
+		protected.GET("/projects/:id/members", projectHandler.ListMembers)
+		protected.POST("/projects/:id/members", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), projectHandler.AddMember)
+		protected.PUT("/projects/:id/members/:userId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), projectHandler.UpdateMember)
+		protected.DELETE("/projects/:id/members/:userId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), projectHandler.RemoveMember)
+
+		// Service accounts
+		protected.GET("/projects/:id/service-accounts", serviceAccountHandler.ListServiceAccounts)
+		protected.POST("/projects/:id/service-accounts", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), serviceAccountHandler.CreateServiceAccount)
+		protected.DELETE("/projects/:id/service-accounts/:accountId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), serviceAccountHandler.DeleteServiceAccount)
+		protected.GET("/projects/:id/service-accounts/:accountId/tokens", serviceAccountHandler.ListTokens)
+		protected.POST("/projects/:id/service-accounts/:accountId/tokens", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), serviceAccountHandler.CreateToken)
+		protected.DELETE("/projects/:id/service-accounts/:accountId/tokens/:tokenId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), serviceAccountHandler.RevokeToken)
 
 		// Test Plans
 		protected.GET("/test-plans", testPlanHandler.ListTestPlans)
 		protected.POST("/test-plans", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testPlanHandler.CreateTestPlan)
 		protected.GET("/test-plans/:id", testPlanHandler.GetTestPlan)
 		protected.PUT("/test-plans/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testPlanHandler.UpdateTestPlan)
+		protected.DELETE("/test-plans/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testPlanHandler.DeleteTestPlan)
 		protected.POST("/test-plans/:id/test-cases", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testPlanHandler.AddTestCase)
+		protected.POST("/test-plans/:id/checklists", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testPlanHandler.AddChecklist)
+		results.POST("/test-plans/:id/automated-results", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testRunHandler.IngestResults)
 
 		// Test Cases
 		protected.GET("/test-cases", testCaseHandler.ListTestCases)
 		protected.POST("/test-cases", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.CreateTestCase)
 		protected.GET("/test-cases/:id", testCaseHandler.GetTestCase)
 		protected.PUT("/test-cases/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.UpdateTestCase)
+		protected.DELETE("/test-cases/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.DeleteTestCase)
+		protected.POST("/test-cases/:id/steps", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.AddStep)
+		protected.PUT("/test-cases/:id/steps/:stepId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.UpdateStep)
+		protected.DELETE("/test-cases/:id/steps/:stepId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.RemoveStep)
+		protected.POST("/test-cases/:id/steps/:stepId/move", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.MoveStep)
+		protected.POST("/projects/:id/test-cases/import", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testCaseHandler.ImportTestCases)
+		protected.GET("/projects/:id/automation-coverage", testCaseHandler.GetAutomationCoverage)
+
+		// Checklists
+		protected.GET("/checklists", checklistHandler.ListChecklists)
+		protected.POST("/checklists", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.CreateChecklist)
+		protected.GET("/checklists/:id", checklistHandler.GetChecklist)
+		protected.PUT("/checklists/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.UpdateChecklist)
+		protected.DELETE("/checklists/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.DeleteChecklist)
+		protected.POST("/checklists/:id/items", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.AddItem)
+		protected.POST("/checklists/:id/items/reorder", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.ReorderItems)
+		protected.PUT("/checklists/:id/items/:itemId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.UpdateItem)
+		protected.DELETE("/checklists/:id/items/:itemId", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), checklistHandler.RemoveItem)
+
+		// Test Strategies
+		protected.GET("/test-strategies", testStrategyHandler.ListTestStrategies)
+		protected.POST("/test-strategies", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testStrategyHandler.CreateTestStrategy)
+		protected.GET("/test-strategies/:id", testStrategyHandler.GetTestStrategy)
+		protected.PUT("/test-strategies/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testStrategyHandler.UpdateTestStrategy)
+		protected.DELETE("/test-strategies/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testStrategyHandler.DeleteTestStrategy)
+
+		// Test Runs
+		protected.GET("/test-runs", testRunHandler.ListTestRuns)
+		results.POST("/test-runs", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testRunHandler.StartTestRun)
+		protected.GET("/test-runs/:id", testRunHandler.GetTestRun)
+		results.POST("/test-runs/:id/results", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testRunHandler.RecordTestResult)
+		results.POST("/test-runs/:id/complete", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testRunHandler.CompleteTestRun)
+		protected.DELETE("/test-runs/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), testRunHandler.DeleteTestRun)
+
+		// Trash
+		protected.GET("/projects/:id/trash", trashHandler.ListTrash)
+		protected.POST("/projects/:id/trash/:entityType/:entityId/restore", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), trashHandler.RestoreItem)
+
+		// History
+		protected.GET("/test-plans/:id/history", historyHandler.ListTestPlanHistory)
+		protected.GET("/test-cases/:id/history", historyHandler.ListTestCaseHistory)
+		protected.GET("/checklists/:id/history", historyHandler.ListChecklistHistory)
+		protected.GET("/test-strategies/:id/history", historyHandler.ListTestStrategyHistory)
+		protected.GET("/test-runs/:id/history", historyHandler.ListTestRunHistory)
+
+		// Comments
+		protected.GET("/comments", commentHandler.ListComments)
+		protected.POST("/comments", commentHandler.CreateComment)
+		protected.GET("/comments/:id", commentHandler.GetComment)
+		protected.PUT("/comments/:id", commentHandler.UpdateComment)
+		protected.DELETE("/comments/:id", commentHandler.DeleteComment)
+		protected.POST("/comments/:id/resolve", commentHandler.ResolveComment)
+		protected.POST("/comments/:id/reopen", commentHandler.ReopenComment)
+
+		// Attachments
+		protected.POST("/test-cases/:id/attachments", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), attachmentHandler.UploadTestCaseAttachment)
+		results.POST("/test-results/:id/attachments", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), attachmentHandler.UploadTestResultAttachment)
+		protected.GET("/attachments/:id/download", attachmentHandler.DownloadAttachment)
+		protected.DELETE("/attachments/:id", middleware.RoleMiddleware(domain.RoleAdmin, domain.RoleUser), attachmentHandler.DeleteAttachment)
 
 		// Export routes
-		protected.POST("/export", exportHandler.Export)
+		protected.GET("/export/formats", exportHandler.ListFormats)
+		reads.POST("/export", exportHandler.Export)
 		protected.GET("/test-plans/:id/export", exportHandler.ExportTestPlan)
 		protected.GET("/test-cases/:id/export", exportHandler.ExportTestCase)
 		protected.GET("/checklists/:id/export", exportHandler.ExportChecklist)
 		protected.GET("/test-strategies/:id/export", exportHandler.ExportTestStrategy)
 		protected.GET("/test-runs/:id/export", exportHandler.ExportTestRun)
+		protected.GET("/projects/:id/test-cases/export", exportHandler.ExportProjectTestCases)
 
 		// Admin only routes
 		admin := protected.Group("/admin")
@@ -113,6 +360,16 @@ func main() {
 		{
 			admin.GET("/users", authHandler.ListUsers)
 			admin.PUT("/users/:id/role", authHandler.UpdateUserRole)
+			admin.GET("/users/:id/mfa", mfaHandler.GetUserStatus)
+			admin.DELETE("/users/:id/mfa", mfaHandler.ResetUser)
+			admin.GET("/mfa-policies", mfaHandler.ListPolicies)
+			admin.PUT("/mfa-policies/:role", mfaHandler.SetPolicy)
+			admin.GET("/invitations", invitationHandler.ListInvitations)
+			admin.POST("/invitations", invitationHandler.CreateInvitation)
+			admin.DELETE("/invitations/:id", invitationHandler.RevokeInvitation)
+			admin.POST("/trash/purge", trashHandler.Purge)
+			admin.GET("/tokens", apiTokenHandler.ListAllTokens)
+			admin.DELETE("/tokens/:id", apiTokenHandler.AdminRevokeToken)
 		}
 	}
 
@@ -127,3 +384,43 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// ssoConfig sets up the OpenID Connect provider and checks the roles it maps to.
+func ssoConfig(cfg config.OIDCConfig) (service.SSOConfig, error) {
+	if cfg.ClientID == "" {
+		return service.SSOConfig{}, errors.New("OIDC_CLIENT_ID is required")
+	}
+
+	validRole := func(role domain.UserRole) bool {
+		return role == domain.RoleAdmin || role == domain.RoleUser || role == domain.RoleGuest
+	}
+
+	roleMapping := make(map[string]domain.UserRole, len(cfg.RoleMapping))
+	for group, role := range cfg.RoleMapping {
+		if !validRole(domain.UserRole(role)) {
+			return service.SSOConfig{}, fmt.Errorf("group %q maps to unknown role %q", group, role)
+		}
+		roleMapping[group] = domain.UserRole(role)
+	}
+
+	defaultRole := domain.UserRole(cfg.DefaultRole)
+	if defaultRole != "" && !validRole(defaultRole) {
+		return service.SSOConfig{}, fmt.Errorf("unknown default role %q", defaultRole)
+	}
+
+	return service.SSOConfig{
+		Provider: oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.IssuerURL,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			GroupsClaim:  cfg.GroupsClaim,
+		}),
+		ProviderName: cfg.ProviderName,
+		RoleMapping:  roleMapping,
+		DefaultRole:  defaultRole,
+
+		TrustUnverifiedEmail: cfg.TrustUnverifiedEmail,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+)
+
+// UserToken is a single-use token mailed to a user to prove they control
+// their email address. Only its hash is stored.
+type UserToken struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(32);not null" json:"purpose"`
+	TokenHash string           `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time        `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Invitation lets an admin create an account for an email address with a
+// preassigned role; the account exists once the invitee sets a password.
+type Invitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Email      string     `gorm:"not null;index" json:"email"`
+	Role       UserRole   `gorm:"type:varchar(20);not null" json:"role"`
+	TokenHash  string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	InvitedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Pending reports whether the invitation can still be accepted at the given time.
+func (i *Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// OIDCAuthRequest remembers a single sign-on attempt while the browser is at
+// the identity provider. The state sent along is stored as a hash; the nonce
+// and PKCE verifier never leave the server in plain form.
+type OIDCAuthRequest struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	StateHash    string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
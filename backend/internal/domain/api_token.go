@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenPrefix starts every API token, which tells them apart from the
+// JWTs issued at login.
+const APITokenPrefix = "tms_"
+
+// IsAPIToken reports whether a bearer token is an API token rather than a JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+type TokenScope string
+
+const (
+	// TokenScopeRead allows GET requests and exports
+	TokenScopeRead TokenScope = "read"
+	// TokenScopeWriteResults allows starting and completing test runs and
+	// recording or ingesting their results; creating test cases while
+	// ingesting needs TokenScopeAdmin
+	TokenScopeWriteResults TokenScope = "write-results"
+	// TokenScopeAdmin allows everything the token's user can do
+	TokenScopeAdmin TokenScope = "admin"
+)
+
+// ValidTokenScope reports whether scope is one of the token scopes.
+func ValidTokenScope(scope TokenScope) bool {
+	switch scope {
+	case TokenScopeRead, TokenScopeWriteResults, TokenScopeAdmin:
+		return true
+	}
+	return false
+}
+
+type TokenScopes []TokenScope
+
+// Allows reports whether the scopes grant required; admin grants every scope.
+func (s TokenScopes) Allows(required TokenScope) bool {
+	for _, scope := range s {
+		if scope == required || scope == TokenScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken is a long-lived token that authenticates as its user, either a
+// person (a personal access token) or a service account. Only a hash of the
+// token is stored; Prefix keeps its first characters so it can be recognised.
+type APIToken struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
+	UserID     uuid.UUID   `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string      `gorm:"not null" json:"name"`
+	Prefix     string      `gorm:"type:varchar(16);not null" json:"prefix"`
+	TokenHash  string      `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes     TokenScopes `gorm:"type:text;serializer:json;not null" json:"scopes"`
+	ExpiresAt  time.Time   `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	CreatedBy  uuid.UUID   `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// Active reports whether the token can still be used at the given time.
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// ServiceAccount is a non-person user belonging to one project, for CI jobs
+// and other integrations. It signs in only with API tokens and its access is
+// limited to its project by a project membership with Role.
+type ServiceAccount struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
+	ProjectID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"project_id"`
+	UserID      uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Name        string      `gorm:"not null" json:"name"`
+	Description string      `json:"description"`
+	Role        ProjectRole `gorm:"->;-:migration" json:"role"` // read from the project membership
+	CreatedBy   uuid.UUID   `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
@@ -0,0 +1,342 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResultFormat string
+
+const (
+	ResultFormatJUnit  ResultFormat = "junit"
+	ResultFormatGoTest ResultFormat = "gotest"
+	ResultFormatTAP    ResultFormat = "tap"
+)
+
+// MaxAutomationKeyLength matches the size of the test_case_automation_ids.key column.
+const MaxAutomationKeyLength = 512
+
+// AutomatedResult is the outcome of one automated test read from a CI report.
+// Key identifies the test across runs and is matched against the automation
+// key of test cases: "classname#name" for JUnit, "package#TestName" for
+// go test and the test description for TAP.
+type AutomatedResult struct {
+	Key      string
+	Name     string
+	Suite    string
+	Status   string
+	Message  string
+	Duration time.Duration
+}
+
+// IngestRequest describes a CI report to record as a test run of a plan.
+type IngestRequest struct {
+	TestPlanID uuid.UUID
+	UserID     uuid.UUID
+	Format     ResultFormat
+	Data       []byte
+	// Name of the created run; defaults to the format and the current time.
+	Name string
+	// AutoCreate creates test cases for results that match no automation key.
+	AutoCreate bool
+}
+
+// IngestReport summarises an ingested CI report. Unmatched lists the keys of
+// results that were not recorded because no test case has that automation key.
+type IngestReport struct {
+	TestRunID        uuid.UUID     `json:"test_run_id"`
+	Format           ResultFormat  `json:"format"`
+	Total            int           `json:"total"`
+	Matched          int           `json:"matched"`
+	TestCasesCreated int           `json:"test_cases_created"`
+	AddedToPlan      int           `json:"added_to_plan"`
+	Unmatched        []string      `json:"unmatched"`
+	Summary          ResultSummary `json:"summary"`
+}
+
+// ParseAutomatedResults reads a CI report. Results are returned in report
+// order; when a key occurs more than once the last outcome wins.
+func ParseAutomatedResults(format ResultFormat, data []byte) ([]AutomatedResult, error) {
+	var results []AutomatedResult
+	var err error
+	switch format {
+	case ResultFormatJUnit:
+		results, err = parseJUnitResults(data)
+	case ResultFormatGoTest:
+		results, err = parseGoTestResults(data)
+	case ResultFormatTAP:
+		results, err = parseTAPResults(data)
+	default:
+		return nil, fmt.Errorf("unsupported result format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errors.New("report contains no test results")
+	}
+
+	// Keep the first position of each key but its last outcome
+	index := make(map[string]int, len(results))
+	unique := results[:0]
+	for _, result := range results {
+		if i, ok := index[result.Key]; ok {
+			unique[i] = result
+			continue
+		}
+		index[result.Key] = len(unique)
+		unique = append(unique, result)
+	}
+	return unique, nil
+}
+
+// DetectResultFormat guesses the format of a CI report from its first
+// non-blank character: XML is JUnit, JSON is go test and anything else TAP.
+func DetectResultFormat(data []byte) ResultFormat {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(data, []byte("<")):
+		return ResultFormatJUnit
+	case bytes.HasPrefix(data, []byte("{")):
+		return ResultFormatGoTest
+	default:
+		return ResultFormatTAP
+	}
+}
+
+// automationKey joins a suite and test name the way automation keys are stored.
+func automationKey(suite, name string) string {
+	if suite == "" {
+		return name
+	}
+	return suite + "#" + name
+}
+
+type junitResultSuite struct {
+	Name      string             `xml:"name,attr"`
+	Suites    []junitResultSuite `xml:"testsuite"`
+	TestCases []struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitMessage `xml:"failure"`
+		Error     *junitMessage `xml:"error"`
+		Skipped   *junitMessage `xml:"skipped"`
+	} `xml:"testcase"`
+}
+
+// parseJUnitResults maps <failure> to fail, <error> to blocked and <skipped>
+// to skipped, the reverse of the JUnit exporter.
+func parseJUnitResults(data []byte) ([]AutomatedResult, error) {
+	var root junitResultSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JUnit XML: %w", err)
+	}
+
+	var results []AutomatedResult
+	var walk func(suite junitResultSuite)
+	walk = func(suite junitResultSuite) {
+		for _, tc := range suite.TestCases {
+			name := strings.TrimSpace(tc.Name)
+			if name == "" {
+				continue
+			}
+			className := strings.TrimSpace(tc.ClassName)
+			if className == "" {
+				className = suite.Name
+			}
+
+			result := AutomatedResult{
+				Key:    automationKey(className, name),
+				Name:   name,
+				Suite:  className,
+				Status: TestResultStatusPass,
+			}
+			if seconds, err := strconv.ParseFloat(tc.Time, 64); err == nil {
+				result.Duration = time.Duration(seconds * float64(time.Second))
+			}
+
+			switch {
+			case tc.Failure != nil:
+				result.Status, result.Message = TestResultStatusFail, junitResultMessage(tc.Failure)
+			case tc.Error != nil:
+				result.Status, result.Message = TestResultStatusBlocked, junitResultMessage(tc.Error)
+			case tc.Skipped != nil:
+				result.Status, result.Message = TestResultStatusSkipped, junitResultMessage(tc.Skipped)
+			}
+			results = append(results, result)
+		}
+		for _, child := range suite.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+func junitResultMessage(m *junitMessage) string {
+	text := strings.TrimSpace(m.Text)
+	if m.Message == "" || strings.Contains(text, m.Message) {
+		return text
+	}
+	return joinLines(m.Message, text)
+}
+
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parseGoTestResults reads the event stream of `go test -json`. Lines that
+// are not JSON, such as build errors, are ignored. Tests that never report an
+// outcome, for example after a panic or timeout, are recorded as failed.
+func parseGoTestResults(data []byte) ([]AutomatedResult, error) {
+	var results []AutomatedResult
+	index := make(map[string]int)
+	output := make(map[string]*strings.Builder)
+	events := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		events++
+		if event.Test == "" {
+			continue
+		}
+
+		key := automationKey(event.Package, event.Test)
+		i, ok := index[key]
+		if !ok {
+			i = len(results)
+			index[key] = i
+			results = append(results, AutomatedResult{
+				Key:     key,
+				Name:    event.Test,
+				Suite:   event.Package,
+				Status:  TestResultStatusFail,
+				Message: "test did not report a result",
+			})
+			output[key] = &strings.Builder{}
+		}
+
+		switch event.Action {
+		case "output":
+			// Skip the framing lines go test prints around every test
+			trimmed := strings.TrimSpace(event.Output)
+			if strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+				continue
+			}
+			output[key].WriteString(event.Output)
+		case "pass", "fail", "skip":
+			result := &results[i]
+			result.Duration = time.Duration(event.Elapsed * float64(time.Second))
+			result.Message = strings.TrimSpace(output[key].String())
+			switch event.Action {
+			case "pass":
+				result.Status = TestResultStatusPass
+			case "fail":
+				result.Status = TestResultStatusFail
+			case "skip":
+				result.Status = TestResultStatusSkipped
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid go test output: %w", err)
+	}
+	if events == 0 {
+		return nil, errors.New("invalid go test output: expected the JSON stream of go test -json")
+	}
+	return results, nil
+}
+
+var tapTestLine = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+
+// parseTAPResults reads TAP version 12 and 13 output. Only top-level test
+// lines are read; indented subtests are covered by their parent. TODO tests
+// and SKIP directives are recorded as skipped, and YAML diagnostics become
+// the result message.
+func parseTAPResults(data []byte) ([]AutomatedResult, error) {
+	var results []AutomatedResult
+	var diagnostics *strings.Builder
+	sawTAP := false
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if diagnostics != nil {
+			if strings.TrimSpace(line) == "..." {
+				if len(results) > 0 {
+					results[len(results)-1].Message = joinLines(results[len(results)-1].Message, strings.TrimRight(diagnostics.String(), "\n"))
+				}
+				diagnostics = nil
+			} else {
+				diagnostics.WriteString(strings.TrimPrefix(line, "  ") + "\n")
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "TAP version"), strings.HasPrefix(line, "1.."):
+			sawTAP = true
+			continue
+		case strings.HasPrefix(line, "Bail out!"):
+			return nil, fmt.Errorf("TAP run bailed out: %s", strings.TrimSpace(strings.TrimPrefix(line, "Bail out!")))
+		case strings.TrimSpace(line) == "---" && strings.HasPrefix(line, " ") && len(results) > 0:
+			diagnostics = &strings.Builder{}
+			continue
+		}
+
+		match := tapTestLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		sawTAP = true
+
+		description, directive := strings.TrimSpace(match[3]), strings.TrimSpace(match[4])
+		if description == "" {
+			description = "test " + match[2]
+		}
+
+		result := AutomatedResult{Key: description, Name: description, Status: TestResultStatusPass}
+		if match[1] == "not ok" {
+			result.Status = TestResultStatusFail
+		}
+
+		upper := strings.ToUpper(directive)
+		switch {
+		case strings.HasPrefix(upper, "SKIP"):
+			result.Status = TestResultStatusSkipped
+			result.Message = strings.TrimSpace(directive[4:])
+		case strings.HasPrefix(upper, "TODO"):
+			result.Status = TestResultStatusSkipped
+			result.Message = strings.TrimSpace("TODO " + strings.TrimSpace(directive[4:]))
+		}
+		results = append(results, result)
+	}
+
+	if !sawTAP {
+		return nil, errors.New("invalid TAP output: no test lines found")
+	}
+	return results, nil
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Comment struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	EntityID   uuid.UUID  `gorm:"type:uuid;not null" json:"entity_id"`
+	EntityType string     `gorm:"not null" json:"entity_type"` // test_plan, test_case, etc.
+	ParentID   *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Content    string     `gorm:"type:text;not null" json:"content"`
+	Resolved   bool       `gorm:"not null;default:false" json:"resolved"`
+	ResolvedBy *uuid.UUID `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedBy  uuid.UUID  `gorm:"type:uuid" json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	User    User      `gorm:"foreignKey:CreatedBy" json:"user,omitempty"`
+	Replies []Comment `gorm:"foreignKey:ParentID" json:"replies,omitempty"`
+}
+
+// CommentThreads groups a flat, chronological comment list into top-level
+// threads with their replies attached.
+func CommentThreads(comments []Comment) []Comment {
+	var threads []Comment
+	index := make(map[uuid.UUID]int)
+
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			index[comment.ID] = len(threads)
+			comment.Replies = nil
+			threads = append(threads, comment)
+		}
+	}
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+
+	return threads
+}
+
+// authorEmail returns the email of a preloaded user, or a placeholder when the
+// user was not loaded or no longer exists.
+func authorEmail(user User) string {
+	if user.Email == "" {
+		return "unknown user"
+	}
+	return user.Email
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the ID of the user performing the request.
+func WithActor(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the acting user, or uuid.Nil for unauthenticated contexts.
+func ActorFromContext(ctx context.Context) uuid.UUID {
+	userID, _ := ctx.Value(actorKey{}).(uuid.UUID)
+	return userID
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error kinds. Every *Error wraps one of them, so callers can test the kind
+// with errors.Is(err, ErrNotFound) without caring about the details.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrForbidden    = errors.New("forbidden")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a failure the caller can act on. Code is a stable machine-readable
+// identifier such as "test_plan_not_found"; Message is meant for people.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  []FieldError
+	// Current is the stored copy of the entity for a version conflict, so
+	// the client can merge its changes.
+	Current interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound reports a missing entity, named in words ("test plan").
+func NotFound(entity string) *Error {
+	return &Error{
+		Kind:    ErrNotFound,
+		Code:    strings.ReplaceAll(entity, " ", "_") + "_not_found",
+		Message: entity + " not found",
+	}
+}
+
+// Conflict reports a request that clashes with the current state, such as a
+// duplicate key or an operation on a completed test run.
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+// VersionConflict reports an update based on an outdated version of the
+// entity. current is the stored copy, or nil when it is not at hand.
+func VersionConflict(entity string, current interface{}) *Error {
+	return &Error{
+		Kind:    ErrConflict,
+		Code:    "version_conflict",
+		Message: entity + " was modified by someone else; reload it and apply your changes again",
+		Current: current,
+	}
+}
+
+// Validation reports invalid input, optionally with the offending fields.
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Code: code, Message: message, Fields: fields}
+}
+
+// Forbidden reports a caller who is authenticated but may not do this.
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+// Unauthorized reports missing or invalid credentials.
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: ErrUnauthorized, Code: code, Message: message}
+}
+
+// IsVersionConflict reports whether err is a version conflict.
+func IsVersionConflict(err error) bool {
+	var domainErr *Error
+	return errors.As(err, &domainErr) && domainErr.Code == "version_conflict"
+}
+
+// IsNotFound reports whether err is a not-found error.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -11,6 +11,9 @@ const (
 	ExportFormatMarkdown ExportFormat = "markdown"
 	ExportFormatHTML     ExportFormat = "html"
 	ExportFormatPDF      ExportFormat = "pdf"
+	ExportFormatJUnit    ExportFormat = "junit"
+	ExportFormatCSV      ExportFormat = "csv"
+	ExportFormatXLSX     ExportFormat = "xlsx"
 )
 
 type ExportRequest struct {
@@ -106,6 +109,8 @@ func (e *MarkdownExporter) ExportTestPlan(plan *TestPlan, includeHistory, includ
 		}
 	}
 
+	e.writeActivity(&sb, plan.History, plan.Comments, includeHistory, includeComments)
+
 	return sb.String(), nil
 }
 
@@ -162,6 +167,8 @@ func (e *MarkdownExporter) ExportTestCase(testCase *TestCase, includeHistory, in
 		sb.WriteString("\n")
 	}
 
+	e.writeActivity(&sb, testCase.History, testCase.Comments, includeHistory, includeComments)
+
 	return sb.String(), nil
 }
 
@@ -193,6 +200,8 @@ func (e *MarkdownExporter) ExportChecklist(checklist *Checklist, includeHistory,
 		}
 	}
 
+	e.writeActivity(&sb, checklist.History, checklist.Comments, includeHistory, includeComments)
+
 	return sb.String(), nil
 }
 
@@ -212,12 +221,23 @@ func (e *MarkdownExporter) ExportTestStrategy(strategy *TestStrategy, includeHis
 		sb.WriteString(strategy.Description + "\n\n")
 	}
 
+	// Sections
+	for _, section := range strategy.Sections.Ordered() {
+		if section.Content == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n\n", section.Title))
+		sb.WriteString(section.Content + "\n\n")
+	}
+
 	// Content
 	if strategy.Content != "" {
 		sb.WriteString("## Strategy Content\n\n")
 		sb.WriteString(strategy.Content + "\n\n")
 	}
 
+	e.writeActivity(&sb, strategy.History, strategy.Comments, includeHistory, includeComments)
+
 	return sb.String(), nil
 }
 
@@ -236,6 +256,7 @@ func (e *MarkdownExporter) ExportTestRun(testRun *TestRun, includeHistory, inclu
 
 	// Test Results Summary
 	if len(testRun.Results) > 0 {
+		pending := 0
 		passed := 0
 		failed := 0
 		blocked := 0
@@ -251,16 +272,19 @@ func (e *MarkdownExporter) ExportTestRun(testRun *TestRun, includeHistory, inclu
 				blocked++
 			case "skipped":
 				skipped++
+			case "pending":
+				pending++
 			}
 		}
 
-		total := passed + failed + blocked + skipped
+		total := passed + failed + blocked + skipped + pending
 		sb.WriteString("## Test Results Summary\n\n")
 		sb.WriteString(fmt.Sprintf("- **Total:** %d\n", total))
 		sb.WriteString(fmt.Sprintf("- **✅ Passed:** %d\n", passed))
 		sb.WriteString(fmt.Sprintf("- **❌ Failed:** %d\n", failed))
 		sb.WriteString(fmt.Sprintf("- **🚫 Blocked:** %d\n", blocked))
 		sb.WriteString(fmt.Sprintf("- **⏭️ Skipped:** %d\n", skipped))
+		sb.WriteString(fmt.Sprintf("- **⏳ Pending:** %d\n", pending))
 
 		if total > 0 {
 			passRate := float64(passed) / float64(total) * 100
@@ -281,12 +305,14 @@ func (e *MarkdownExporter) ExportTestRun(testRun *TestRun, includeHistory, inclu
 				statusIcon = "🚫"
 			case "skipped":
 				statusIcon = "⏭️"
+			case "pending":
+				statusIcon = "⏳"
 			}
 
 			entityName := e.getEntityName(&result)
 			sb.WriteString(fmt.Sprintf("### %d. %s %s\n", i+1, statusIcon, entityName))
 			sb.WriteString(fmt.Sprintf("**Status:** %s\n", result.Status))
-			sb.WriteString(fmt.Sprintf("**Executed By:** %s\n", result.ExecutedBy))
+			sb.WriteString(fmt.Sprintf("**Executed By:** %s\n", result.ExecutorName()))
 			sb.WriteString(fmt.Sprintf("**Executed At:** %s\n", result.ExecutedAt.Format("2006-01-02 15:04")))
 
 			if result.Comments != "" {
@@ -296,9 +322,67 @@ func (e *MarkdownExporter) ExportTestRun(testRun *TestRun, includeHistory, inclu
 		}
 	}
 
+	e.writeActivity(&sb, testRun.History, testRun.Comments, includeHistory, includeComments)
+
 	return sb.String(), nil
 }
 
+func (e *MarkdownExporter) writeActivity(sb *strings.Builder, history []History, comments []Comment, includeHistory, includeComments bool) {
+	if includeHistory {
+		sb.WriteString("## Change History\n\n")
+		if len(history) == 0 {
+			sb.WriteString("No changes recorded.\n\n")
+		}
+		for _, entry := range history {
+			sb.WriteString(fmt.Sprintf("- **%s** %s by %s\n",
+				entry.ChangedAt.Format("2006-01-02 15:04"),
+				entry.Action,
+				authorEmail(entry.User)))
+
+			fields, changes := entry.ChangedFields()
+			for _, field := range fields {
+				change := changes[field]
+				sb.WriteString(fmt.Sprintf("  - `%s`: %s → %s\n",
+					field,
+					FormatChangeValue(change.Old),
+					FormatChangeValue(change.New)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if includeComments {
+		sb.WriteString("## Comments\n\n")
+		if len(comments) == 0 {
+			sb.WriteString("No comments.\n\n")
+		}
+		for _, thread := range CommentThreads(comments) {
+			status := ""
+			if thread.Resolved {
+				status = " — resolved"
+			}
+			sb.WriteString(fmt.Sprintf("**%s** (%s)%s:\n",
+				authorEmail(thread.User),
+				thread.CreatedAt.Format("2006-01-02 15:04"),
+				status))
+			for _, line := range strings.Split(thread.Content, "\n") {
+				sb.WriteString("> " + line + "\n")
+			}
+
+			for _, reply := range thread.Replies {
+				sb.WriteString(">\n")
+				sb.WriteString(fmt.Sprintf("> **%s** (%s):\n",
+					authorEmail(reply.User),
+					reply.CreatedAt.Format("2006-01-02 15:04")))
+				for _, line := range strings.Split(reply.Content, "\n") {
+					sb.WriteString(">> " + line + "\n")
+				}
+			}
+			sb.WriteString("\n")
+		}
+	}
+}
+
 func (e *MarkdownExporter) getEntityName(result *TestResult) string {
 	if result.TestCaseID != nil {
 		return "Test Case"
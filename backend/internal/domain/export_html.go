@@ -0,0 +1,466 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+const htmlStyles = `
+body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; color: #1f2933; max-width: 960px; margin: 2rem auto; padding: 0 1.5rem; line-height: 1.5; }
+h1 { border-bottom: 2px solid #d9e2ec; padding-bottom: .5rem; }
+h2 { margin-top: 2rem; border-bottom: 1px solid #e4e7eb; padding-bottom: .25rem; }
+h3 { margin-bottom: .25rem; }
+.meta { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; margin: 1rem 0; }
+.meta dt { font-weight: 600; color: #52606d; }
+.meta dd { margin: 0; }
+.text { white-space: pre-wrap; }
+.expected { color: #52606d; font-style: italic; }
+table { width: 100%; border-collapse: collapse; margin: 1rem 0; }
+th, td { border: 1px solid #d9e2ec; padding: .4rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f0f4f8; }
+.status { display: inline-block; padding: .1rem .5rem; border-radius: 999px; font-size: .85em; font-weight: 600; color: #fff; background: #9aa5b1; }
+.status-pass { background: #2f9e44; }
+.status-fail { background: #e03131; }
+.status-blocked { background: #e8590c; }
+.status-skipped { background: #7b8794; }
+.status-pending { background: #1c7ed6; }
+.summary { display: flex; flex-wrap: wrap; gap: 1rem; margin: 1rem 0; }
+.summary div { border: 1px solid #d9e2ec; border-radius: 6px; padding: .5rem 1rem; min-width: 90px; }
+.summary strong { display: block; font-size: 1.4em; }
+.pass-rate { height: 18px; background: #e4e7eb; border-radius: 9px; overflow: hidden; display: flex; }
+.pass-rate span { display: block; height: 100%; }
+.history li, .comments li { margin-bottom: .5rem; }
+.comment { border-left: 3px solid #d9e2ec; padding-left: .75rem; margin: .75rem 0; }
+.comment .reply { margin-left: 1.5rem; }
+.comment-meta { color: #52606d; font-size: .9em; }
+@media print {
+  body { margin: 0; max-width: none; }
+  h2 { page-break-after: avoid; }
+  tr, .comment { page-break-inside: avoid; }
+  .status, .pass-rate span { -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+}
+`
+
+// statusColors mirrors the .status-* classes for inline elements such as the
+// pass-rate bar.
+var statusColors = map[string]string{
+	TestResultStatusPass:    "#2f9e44",
+	TestResultStatusFail:    "#e03131",
+	TestResultStatusBlocked: "#e8590c",
+	TestResultStatusSkipped: "#7b8794",
+	TestResultStatusPending: "#1c7ed6",
+}
+
+// ResultSummary counts the results of a test run by status.
+type ResultSummary struct {
+	Total   int
+	Passed  int
+	Failed  int
+	Blocked int
+	Skipped int
+	Pending int
+}
+
+// SummarizeResults tallies results by status.
+func SummarizeResults(results []TestResult) ResultSummary {
+	var summary ResultSummary
+	for _, result := range results {
+		switch result.Status {
+		case TestResultStatusPass:
+			summary.Passed++
+		case TestResultStatusFail:
+			summary.Failed++
+		case TestResultStatusBlocked:
+			summary.Blocked++
+		case TestResultStatusSkipped:
+			summary.Skipped++
+		case TestResultStatusPending:
+			summary.Pending++
+		default:
+			continue
+		}
+		summary.Total++
+	}
+	return summary
+}
+
+// PassRate returns the share of passed results in percent.
+func (s ResultSummary) PassRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Total) * 100
+}
+
+// ResultTitle names the test case or checklist item a result was recorded for.
+func ResultTitle(result *TestResult) string {
+	if result.TestCase != nil && result.TestCase.Title != "" {
+		return result.TestCase.Title
+	}
+	if result.ChecklistItem != nil && result.ChecklistItem.Description != "" {
+		return result.ChecklistItem.Description
+	}
+	if result.TestCaseID != nil {
+		return "Test Case"
+	} else if result.ChecklistItemID != nil {
+		return "Checklist Item"
+	}
+	return "Unknown Entity"
+}
+
+// HTMLExporter renders entities as standalone, printable HTML documents.
+type HTMLExporter struct{}
+
+func NewHTMLExporter() *HTMLExporter {
+	return &HTMLExporter{}
+}
+
+func (e *HTMLExporter) ExportTestPlan(plan *TestPlan, includeHistory, includeComments bool) (string, error) {
+	var sb strings.Builder
+	title := "Test Plan: " + plan.Name
+	e.writeHeader(&sb, title)
+
+	meta := [][2]string{
+		{"ID", plan.ID.String()},
+		{"Project ID", plan.ProjectID.String()},
+		{"Status", plan.Status},
+	}
+	if !plan.Deadline.IsZero() {
+		meta = append(meta, [2]string{"Deadline", plan.Deadline.Format("2006-01-02 15:04")})
+	}
+	meta = append(meta,
+		[2]string{"Created", plan.CreatedAt.Format("2006-01-02 15:04")},
+		[2]string{"Last Updated", plan.UpdatedAt.Format("2006-01-02 15:04")},
+	)
+	e.writeMeta(&sb, meta)
+
+	e.writeTextSection(&sb, "Description", plan.Description)
+
+	if len(plan.TestCases) > 0 {
+		sb.WriteString("<h2>Test Cases</h2>\n")
+		for i, testCase := range plan.TestCases {
+			sb.WriteString(fmt.Sprintf("<h3>%d. %s</h3>\n", i+1, esc(testCase.Title)))
+			if testCase.Description != "" {
+				sb.WriteString(fmt.Sprintf("<p class=\"text\">%s</p>\n", esc(testCase.Description)))
+			}
+			if testCase.PreSteps != "" {
+				sb.WriteString(fmt.Sprintf("<p><strong>Pre-Steps:</strong></p>\n<p class=\"text\">%s</p>\n", esc(testCase.PreSteps)))
+			}
+			e.writeSteps(&sb, testCase.Steps)
+			if testCase.ExpectedResult != "" {
+				sb.WriteString(fmt.Sprintf("<p><strong>Expected Result:</strong> <span class=\"text\">%s</span></p>\n", esc(testCase.ExpectedResult)))
+			}
+		}
+	}
+
+	if len(plan.Checklists) > 0 {
+		sb.WriteString("<h2>Checklists</h2>\n")
+		for i, checklist := range plan.Checklists {
+			sb.WriteString(fmt.Sprintf("<h3>%d. %s</h3>\n", i+1, esc(checklist.Name)))
+			if checklist.Description != "" {
+				sb.WriteString(fmt.Sprintf("<p class=\"text\">%s</p>\n", esc(checklist.Description)))
+			}
+			e.writeChecklistItems(&sb, checklist.Items)
+		}
+	}
+
+	e.writeActivity(&sb, plan.History, plan.Comments, includeHistory, includeComments)
+	e.writeFooter(&sb)
+
+	return sb.String(), nil
+}
+
+func (e *HTMLExporter) ExportTestCase(testCase *TestCase, includeHistory, includeComments bool) (string, error) {
+	var sb strings.Builder
+	e.writeHeader(&sb, "Test Case: "+testCase.Title)
+
+	e.writeMeta(&sb, [][2]string{
+		{"ID", testCase.ID.String()},
+		{"Project ID", testCase.ProjectID.String()},
+		{"Created", testCase.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", testCase.UpdatedAt.Format("2006-01-02 15:04")},
+	})
+
+	e.writeTextSection(&sb, "Description", testCase.Description)
+	e.writeTextSection(&sb, "Pre-Steps", testCase.PreSteps)
+
+	if len(testCase.Steps) > 0 {
+		sb.WriteString("<h2>Test Steps</h2>\n")
+		e.writeSteps(&sb, testCase.Steps)
+	}
+
+	e.writeTextSection(&sb, "Expected Result", testCase.ExpectedResult)
+
+	if len(testCase.Attachments) > 0 {
+		sb.WriteString("<h2>Attachments</h2>\n<ul>\n")
+		for _, attachment := range testCase.Attachments {
+			sb.WriteString(fmt.Sprintf("<li><strong>%s</strong> (%s, %d bytes)</li>\n",
+				esc(attachment.FileName),
+				esc(attachment.MimeType),
+				attachment.FileSize))
+		}
+		sb.WriteString("</ul>\n")
+	}
+
+	e.writeActivity(&sb, testCase.History, testCase.Comments, includeHistory, includeComments)
+	e.writeFooter(&sb)
+
+	return sb.String(), nil
+}
+
+func (e *HTMLExporter) ExportChecklist(checklist *Checklist, includeHistory, includeComments bool) (string, error) {
+	var sb strings.Builder
+	e.writeHeader(&sb, "Checklist: "+checklist.Name)
+
+	e.writeMeta(&sb, [][2]string{
+		{"ID", checklist.ID.String()},
+		{"Project ID", checklist.ProjectID.String()},
+		{"Created", checklist.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", checklist.UpdatedAt.Format("2006-01-02 15:04")},
+	})
+
+	e.writeTextSection(&sb, "Description", checklist.Description)
+
+	if len(checklist.Items) > 0 {
+		sb.WriteString("<h2>Checklist Items</h2>\n")
+		e.writeChecklistItems(&sb, checklist.Items)
+	}
+
+	e.writeActivity(&sb, checklist.History, checklist.Comments, includeHistory, includeComments)
+	e.writeFooter(&sb)
+
+	return sb.String(), nil
+}
+
+func (e *HTMLExporter) ExportTestStrategy(strategy *TestStrategy, includeHistory, includeComments bool) (string, error) {
+	var sb strings.Builder
+	e.writeHeader(&sb, "Test Strategy: "+strategy.Name)
+
+	e.writeMeta(&sb, [][2]string{
+		{"ID", strategy.ID.String()},
+		{"Project ID", strategy.ProjectID.String()},
+		{"Created", strategy.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", strategy.UpdatedAt.Format("2006-01-02 15:04")},
+	})
+
+	e.writeTextSection(&sb, "Description", strategy.Description)
+	for _, section := range strategy.Sections.Ordered() {
+		e.writeTextSection(&sb, section.Title, section.Content)
+	}
+	e.writeTextSection(&sb, "Strategy Content", strategy.Content)
+
+	e.writeActivity(&sb, strategy.History, strategy.Comments, includeHistory, includeComments)
+	e.writeFooter(&sb)
+
+	return sb.String(), nil
+}
+
+func (e *HTMLExporter) ExportTestRun(testRun *TestRun, includeHistory, includeComments bool) (string, error) {
+	var sb strings.Builder
+	e.writeHeader(&sb, "Test Run: "+testRun.Name)
+
+	meta := [][2]string{
+		{"ID", testRun.ID.String()},
+		{"Test Plan ID", testRun.TestPlanID.String()},
+		{"Started", testRun.StartedAt.Format("2006-01-02 15:04")},
+	}
+	if testRun.CompletedAt != nil {
+		meta = append(meta, [2]string{"Completed", testRun.CompletedAt.Format("2006-01-02 15:04")})
+	}
+	e.writeMeta(&sb, meta)
+
+	if len(testRun.Results) > 0 {
+		summary := SummarizeResults(testRun.Results)
+
+		sb.WriteString("<h2>Test Results Summary</h2>\n<div class=\"summary\">\n")
+		for _, stat := range []struct {
+			label string
+			count int
+		}{
+			{"Total", summary.Total},
+			{"Passed", summary.Passed},
+			{"Failed", summary.Failed},
+			{"Blocked", summary.Blocked},
+			{"Skipped", summary.Skipped},
+			{"Pending", summary.Pending},
+		} {
+			sb.WriteString(fmt.Sprintf("<div><strong>%d</strong>%s</div>\n", stat.count, stat.label))
+		}
+		sb.WriteString("</div>\n")
+
+		if summary.Total > 0 {
+			sb.WriteString(fmt.Sprintf("<p><strong>Pass Rate:</strong> %.1f%%</p>\n", summary.PassRate()))
+			sb.WriteString("<div class=\"pass-rate\">\n")
+			for _, segment := range []struct {
+				status string
+				count  int
+			}{
+				{TestResultStatusPass, summary.Passed},
+				{TestResultStatusFail, summary.Failed},
+				{TestResultStatusBlocked, summary.Blocked},
+				{TestResultStatusSkipped, summary.Skipped},
+				{TestResultStatusPending, summary.Pending},
+			} {
+				if segment.count == 0 {
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("<span title=\"%s: %d\" style=\"width: %.2f%%; background: %s\"></span>\n",
+					segment.status,
+					segment.count,
+					float64(segment.count)/float64(summary.Total)*100,
+					statusColors[segment.status]))
+			}
+			sb.WriteString("</div>\n")
+		}
+
+		sb.WriteString("<h2>Detailed Results</h2>\n")
+		sb.WriteString("<table>\n<thead><tr><th>#</th><th>Test</th><th>Status</th><th>Executed By</th><th>Executed At</th><th>Comments</th></tr></thead>\n<tbody>\n")
+		for i, result := range testRun.Results {
+			executedBy, executedAt := "", ""
+			if !result.ExecutedAt.IsZero() {
+				executedBy = result.ExecutorName()
+				executedAt = result.ExecutedAt.Format("2006-01-02 15:04")
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td class=\"text\">%s</td></tr>\n",
+				i+1,
+				esc(ResultTitle(&result)),
+				statusBadge(result.Status),
+				esc(executedBy),
+				executedAt,
+				esc(result.Comments)))
+		}
+		sb.WriteString("</tbody>\n</table>\n")
+	}
+
+	e.writeActivity(&sb, testRun.History, testRun.Comments, includeHistory, includeComments)
+	e.writeFooter(&sb)
+
+	return sb.String(), nil
+}
+
+func (e *HTMLExporter) writeHeader(sb *strings.Builder, title string) {
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n", esc(title)))
+	sb.WriteString("<style>" + htmlStyles + "</style>\n")
+	sb.WriteString("</head>\n<body>\n")
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", esc(title)))
+}
+
+func (e *HTMLExporter) writeFooter(sb *strings.Builder) {
+	sb.WriteString("</body>\n</html>\n")
+}
+
+func (e *HTMLExporter) writeMeta(sb *strings.Builder, fields [][2]string) {
+	sb.WriteString("<dl class=\"meta\">\n")
+	for _, field := range fields {
+		sb.WriteString(fmt.Sprintf("<dt>%s</dt><dd>%s</dd>\n", esc(field[0]), esc(field[1])))
+	}
+	sb.WriteString("</dl>\n")
+}
+
+func (e *HTMLExporter) writeTextSection(sb *strings.Builder, title, content string) {
+	if content == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("<h2>%s</h2>\n<p class=\"text\">%s</p>\n", esc(title), esc(content)))
+}
+
+func (e *HTMLExporter) writeSteps(sb *strings.Builder, steps []TestStep) {
+	if len(steps) == 0 {
+		return
+	}
+	sb.WriteString("<ol>\n")
+	for _, step := range steps {
+		sb.WriteString(fmt.Sprintf("<li><span class=\"text\">%s</span>", esc(step.Description)))
+		if step.ExpectedResult != "" {
+			sb.WriteString(fmt.Sprintf("<br><span class=\"expected\">Expected: %s</span>", esc(step.ExpectedResult)))
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</ol>\n")
+}
+
+func (e *HTMLExporter) writeChecklistItems(sb *strings.Builder, items []ChecklistItem) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString("<ol>\n")
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("<li>&#9744; %s", esc(item.Description)))
+		if item.ExpectedResult != "" {
+			sb.WriteString(fmt.Sprintf("<br><span class=\"expected\">Expected: %s</span>", esc(item.ExpectedResult)))
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</ol>\n")
+}
+
+func (e *HTMLExporter) writeActivity(sb *strings.Builder, history []History, comments []Comment, includeHistory, includeComments bool) {
+	if includeHistory {
+		sb.WriteString("<h2>Change History</h2>\n")
+		if len(history) == 0 {
+			sb.WriteString("<p>No changes recorded.</p>\n")
+		} else {
+			sb.WriteString("<ul class=\"history\">\n")
+			for _, entry := range history {
+				sb.WriteString(fmt.Sprintf("<li><strong>%s</strong> %s by %s",
+					entry.ChangedAt.Format("2006-01-02 15:04"),
+					esc(entry.Action),
+					esc(authorEmail(entry.User))))
+
+				fields, changes := entry.ChangedFields()
+				if len(fields) > 0 {
+					sb.WriteString("\n<ul>\n")
+					for _, field := range fields {
+						change := changes[field]
+						sb.WriteString(fmt.Sprintf("<li><code>%s</code>: %s &rarr; %s</li>\n",
+							esc(field),
+							esc(FormatChangeValue(change.Old)),
+							esc(FormatChangeValue(change.New))))
+					}
+					sb.WriteString("</ul>\n")
+				}
+				sb.WriteString("</li>\n")
+			}
+			sb.WriteString("</ul>\n")
+		}
+	}
+
+	if includeComments {
+		sb.WriteString("<h2>Comments</h2>\n")
+		if len(comments) == 0 {
+			sb.WriteString("<p>No comments.</p>\n")
+		}
+		for _, thread := range CommentThreads(comments) {
+			status := ""
+			if thread.Resolved {
+				status = " &mdash; resolved"
+			}
+			sb.WriteString("<div class=\"comment\">\n")
+			sb.WriteString(fmt.Sprintf("<div class=\"comment-meta\"><strong>%s</strong> (%s)%s</div>\n",
+				esc(authorEmail(thread.User)),
+				thread.CreatedAt.Format("2006-01-02 15:04"),
+				status))
+			sb.WriteString(fmt.Sprintf("<p class=\"text\">%s</p>\n", esc(thread.Content)))
+
+			for _, reply := range thread.Replies {
+				sb.WriteString("<div class=\"reply\">\n")
+				sb.WriteString(fmt.Sprintf("<div class=\"comment-meta\"><strong>%s</strong> (%s)</div>\n",
+					esc(authorEmail(reply.User)),
+					reply.CreatedAt.Format("2006-01-02 15:04")))
+				sb.WriteString(fmt.Sprintf("<p class=\"text\">%s</p>\n", esc(reply.Content)))
+				sb.WriteString("</div>\n")
+			}
+			sb.WriteString("</div>\n")
+		}
+	}
+}
+
+func statusBadge(status string) string {
+	return fmt.Sprintf("<span class=\"status status-%s\">%s</span>", esc(status), esc(status))
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errJUnitRunsOnly = errors.New("JUnit export is only available for test runs")
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	ID         string          `xml:"id,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitExporter renders test runs as JUnit XML so CI dashboards can show
+// manual results next to automated ones. Only test runs are supported.
+type JUnitExporter struct{}
+
+func NewJUnitExporter() *JUnitExporter {
+	return &JUnitExporter{}
+}
+
+func (e *JUnitExporter) ExportTestPlan(plan *TestPlan, includeHistory, includeComments bool) (string, error) {
+	return "", errJUnitRunsOnly
+}
+
+func (e *JUnitExporter) ExportTestCase(testCase *TestCase, includeHistory, includeComments bool) (string, error) {
+	return "", errJUnitRunsOnly
+}
+
+func (e *JUnitExporter) ExportChecklist(checklist *Checklist, includeHistory, includeComments bool) (string, error) {
+	return "", errJUnitRunsOnly
+}
+
+func (e *JUnitExporter) ExportTestStrategy(strategy *TestStrategy, includeHistory, includeComments bool) (string, error) {
+	return "", errJUnitRunsOnly
+}
+
+// ExportTestRun maps the run to a single testsuite and every result to a
+// testcase: fail becomes <failure>, blocked becomes <error>, skipped and
+// pending become <skipped>. The result comments are used as the message.
+func (e *JUnitExporter) ExportTestRun(testRun *TestRun, includeHistory, includeComments bool) (string, error) {
+	suite := junitTestSuite{
+		Name:      testRun.Name,
+		ID:        testRun.ID.String(),
+		Timestamp: testRun.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		Time:      junitSeconds(runDuration(testRun)),
+		Properties: []junitProperty{
+			{Name: "test_run_id", Value: testRun.ID.String()},
+			{Name: "test_plan_id", Value: testRun.TestPlanID.String()},
+		},
+	}
+
+	for _, result := range testRun.Results {
+		testCase := junitTestCase{
+			Name:      ResultTitle(&result),
+			ClassName: testRun.Name,
+			Time:      junitSeconds(0),
+		}
+
+		switch result.Status {
+		case TestResultStatusFail:
+			testCase.Failure = &junitMessage{Message: result.Comments, Type: "failure", Text: result.Comments}
+			suite.Failures++
+		case TestResultStatusBlocked:
+			testCase.Error = &junitMessage{Message: result.Comments, Type: "blocked", Text: result.Comments}
+			suite.Errors++
+		case TestResultStatusSkipped:
+			testCase.Skipped = &junitMessage{Message: result.Comments}
+			suite.Skipped++
+		case TestResultStatusPending:
+			message := result.Comments
+			if message == "" {
+				message = "not executed"
+			}
+			testCase.Skipped = &junitMessage{Message: message}
+			suite.Skipped++
+		default:
+			testCase.SystemOut = result.Comments
+		}
+
+		if !result.ExecutedAt.IsZero() {
+			testCase.SystemOut = joinLines(testCase.SystemOut,
+				fmt.Sprintf("Executed by %s at %s", result.ExecutorName(), result.ExecutedAt.UTC().Format(time.RFC3339)))
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	report := junitTestSuites{
+		Name:     testRun.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data) + "\n", nil
+}
+
+func runDuration(testRun *TestRun) time.Duration {
+	if testRun.CompletedAt == nil || testRun.CompletedAt.Before(testRun.StartedAt) {
+		return 0
+	}
+	return testRun.CompletedAt.Sub(testRun.StartedAt)
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func joinLines(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
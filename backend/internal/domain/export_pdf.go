@@ -0,0 +1,775 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/pkg/pdf"
+)
+
+// AttachmentReader opens stored attachment files so exporters can embed them.
+type AttachmentReader interface {
+	Get(filepath string) (io.ReadCloser, error)
+}
+
+// PDFExporter renders entities as paginated PDF reports with a cover page,
+// a table of contents and embedded image attachments.
+type PDFExporter struct {
+	files AttachmentReader
+}
+
+func NewPDFExporter(files AttachmentReader) *PDFExporter {
+	return &PDFExporter{files: files}
+}
+
+func (e *PDFExporter) ExportTestPlan(plan *TestPlan, includeHistory, includeComments bool) (string, error) {
+	r := e.newReport("Test Plan", plan.Name)
+
+	meta := [][2]string{
+		{"ID", plan.ID.String()},
+		{"Project ID", plan.ProjectID.String()},
+		{"Status", plan.Status},
+	}
+	if !plan.Deadline.IsZero() {
+		meta = append(meta, [2]string{"Deadline", plan.Deadline.Format("2006-01-02 15:04")})
+	}
+	meta = append(meta,
+		[2]string{"Created", plan.CreatedAt.Format("2006-01-02 15:04")},
+		[2]string{"Last Updated", plan.UpdatedAt.Format("2006-01-02 15:04")},
+		[2]string{"Test Cases", fmt.Sprint(len(plan.TestCases))},
+		[2]string{"Checklists", fmt.Sprint(len(plan.Checklists))},
+	)
+	r.cover(meta)
+
+	r.heading("Overview", 1)
+	r.fields(meta)
+	r.textSection("Description", plan.Description)
+
+	if len(plan.TestCases) > 0 {
+		r.heading("Test Cases", 1)
+		for i, testCase := range plan.TestCases {
+			r.heading(fmt.Sprintf("%d. %s", i+1, testCase.Title), 2)
+			r.labeled("Description", testCase.Description)
+			r.labeled("Pre-Steps", testCase.PreSteps)
+			r.steps(testCase.Steps)
+			r.labeled("Expected Result", testCase.ExpectedResult)
+			r.images(testCase.Attachments, "")
+		}
+	}
+
+	if len(plan.Checklists) > 0 {
+		r.heading("Checklists", 1)
+		for i, checklist := range plan.Checklists {
+			r.heading(fmt.Sprintf("%d. %s", i+1, checklist.Name), 2)
+			r.labeled("Description", checklist.Description)
+			r.checklistItems(checklist.Items)
+		}
+	}
+
+	r.activity(plan.History, plan.Comments, includeHistory, includeComments)
+
+	return r.finish()
+}
+
+func (e *PDFExporter) ExportTestCase(testCase *TestCase, includeHistory, includeComments bool) (string, error) {
+	r := e.newReport("Test Case", testCase.Title)
+
+	meta := [][2]string{
+		{"ID", testCase.ID.String()},
+		{"Project ID", testCase.ProjectID.String()},
+		{"Created", testCase.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", testCase.UpdatedAt.Format("2006-01-02 15:04")},
+	}
+	r.cover(meta)
+
+	r.heading("Overview", 1)
+	r.fields(meta)
+	r.textSection("Description", testCase.Description)
+	r.textSection("Pre-Steps", testCase.PreSteps)
+
+	if len(testCase.Steps) > 0 {
+		r.heading("Test Steps", 1)
+		r.steps(testCase.Steps)
+	}
+
+	r.textSection("Expected Result", testCase.ExpectedResult)
+
+	if len(testCase.Attachments) > 0 {
+		r.heading("Attachments", 1)
+		rows := make([][]string, len(testCase.Attachments))
+		for i, attachment := range testCase.Attachments {
+			rows[i] = []string{attachment.FileName, attachment.MimeType, fmt.Sprintf("%d bytes", attachment.FileSize)}
+		}
+		r.table([]pdfColumn{{"File", 0.5}, {"Type", 0.3}, {"Size", 0.2}}, rows, nil)
+		r.images(testCase.Attachments, "")
+	}
+
+	r.activity(testCase.History, testCase.Comments, includeHistory, includeComments)
+
+	return r.finish()
+}
+
+func (e *PDFExporter) ExportChecklist(checklist *Checklist, includeHistory, includeComments bool) (string, error) {
+	r := e.newReport("Checklist", checklist.Name)
+
+	meta := [][2]string{
+		{"ID", checklist.ID.String()},
+		{"Project ID", checklist.ProjectID.String()},
+		{"Created", checklist.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", checklist.UpdatedAt.Format("2006-01-02 15:04")},
+	}
+	r.cover(meta)
+
+	r.heading("Overview", 1)
+	r.fields(meta)
+	r.textSection("Description", checklist.Description)
+
+	if len(checklist.Items) > 0 {
+		r.heading("Checklist Items", 1)
+		r.checklistItems(checklist.Items)
+	}
+
+	r.activity(checklist.History, checklist.Comments, includeHistory, includeComments)
+
+	return r.finish()
+}
+
+func (e *PDFExporter) ExportTestStrategy(strategy *TestStrategy, includeHistory, includeComments bool) (string, error) {
+	r := e.newReport("Test Strategy", strategy.Name)
+
+	meta := [][2]string{
+		{"ID", strategy.ID.String()},
+		{"Project ID", strategy.ProjectID.String()},
+		{"Created", strategy.CreatedAt.Format("2006-01-02 15:04")},
+		{"Last Updated", strategy.UpdatedAt.Format("2006-01-02 15:04")},
+	}
+	r.cover(meta)
+
+	r.heading("Overview", 1)
+	r.fields(meta)
+	r.textSection("Description", strategy.Description)
+	for _, section := range strategy.Sections.Ordered() {
+		r.textSection(section.Title, section.Content)
+	}
+	r.textSection("Strategy Content", strategy.Content)
+
+	r.activity(strategy.History, strategy.Comments, includeHistory, includeComments)
+
+	return r.finish()
+}
+
+func (e *PDFExporter) ExportTestRun(testRun *TestRun, includeHistory, includeComments bool) (string, error) {
+	r := e.newReport("Test Run", testRun.Name)
+	summary := SummarizeResults(testRun.Results)
+
+	meta := [][2]string{
+		{"ID", testRun.ID.String()},
+		{"Test Plan ID", testRun.TestPlanID.String()},
+		{"Started", testRun.StartedAt.Format("2006-01-02 15:04")},
+	}
+	if testRun.CompletedAt != nil {
+		meta = append(meta, [2]string{"Completed", testRun.CompletedAt.Format("2006-01-02 15:04")})
+	} else {
+		meta = append(meta, [2]string{"Completed", "in progress"})
+	}
+	meta = append(meta,
+		[2]string{"Results", fmt.Sprint(summary.Total)},
+		[2]string{"Pass Rate", fmt.Sprintf("%.1f%%", summary.PassRate())},
+	)
+	r.cover(meta)
+
+	r.heading("Overview", 1)
+	r.fields(meta)
+
+	if len(testRun.Results) > 0 {
+		r.heading("Test Results Summary", 1)
+		r.fields([][2]string{
+			{"Total", fmt.Sprint(summary.Total)},
+			{"Passed", fmt.Sprint(summary.Passed)},
+			{"Failed", fmt.Sprint(summary.Failed)},
+			{"Blocked", fmt.Sprint(summary.Blocked)},
+			{"Skipped", fmt.Sprint(summary.Skipped)},
+			{"Pending", fmt.Sprint(summary.Pending)},
+			{"Pass Rate", fmt.Sprintf("%.1f%%", summary.PassRate())},
+		})
+		r.passRateBar(summary)
+
+		r.heading("Detailed Results", 1)
+		rows := make([][]string, len(testRun.Results))
+		for i, result := range testRun.Results {
+			executedBy, executedAt := "", ""
+			if !result.ExecutedAt.IsZero() {
+				executedBy = result.ExecutorName()
+				executedAt = result.ExecutedAt.Format("2006-01-02 15:04")
+			}
+			rows[i] = []string{fmt.Sprint(i + 1), ResultTitle(&result), result.Status, executedBy, executedAt, result.Comments}
+		}
+		r.table([]pdfColumn{
+			{"#", 0.05},
+			{"Test", 0.25},
+			{"Status", 0.1},
+			{"Executed By", 0.2},
+			{"Executed At", 0.14},
+			{"Comments", 0.26},
+		}, rows, func(row, col int) *pdf.Color {
+			if col != 2 {
+				return nil
+			}
+			color := pdfStatusColor(testRun.Results[row].Status)
+			return &color
+		})
+
+		evidence := false
+		for _, result := range testRun.Results {
+			if hasImages(result.Attachments) {
+				evidence = true
+				break
+			}
+		}
+		if evidence {
+			r.heading("Evidence", 1)
+			for i, result := range testRun.Results {
+				r.images(result.Attachments, fmt.Sprintf("#%d %s", i+1, ResultTitle(&result)))
+			}
+		}
+	}
+
+	r.activity(testRun.History, testRun.Comments, includeHistory, includeComments)
+
+	return r.finish()
+}
+
+const (
+	pdfMargin       = 56.0
+	pdfContentWidth = pdf.PageWidth - 2*pdfMargin
+	pdfBottom       = pdf.PageHeight - pdfMargin
+	pdfBodySize     = 10.0
+	pdfLeading      = 1.4
+)
+
+var (
+	pdfTextColor   = pdf.Hex(0x1f2933)
+	pdfMutedColor  = pdf.Hex(0x52606d)
+	pdfBorderColor = pdf.Hex(0xd9e2ec)
+	pdfHeaderFill  = pdf.Hex(0xf0f4f8)
+	pdfAccentColor = pdf.Hex(0x1c7ed6)
+)
+
+func pdfStatusColor(status string) pdf.Color {
+	switch status {
+	case TestResultStatusPass:
+		return pdf.Hex(0x2f9e44)
+	case TestResultStatusFail:
+		return pdf.Hex(0xe03131)
+	case TestResultStatusBlocked:
+		return pdf.Hex(0xe8590c)
+	case TestResultStatusSkipped:
+		return pdf.Hex(0x7b8794)
+	case TestResultStatusPending:
+		return pdf.Hex(0x1c7ed6)
+	}
+	return pdfTextColor
+}
+
+type pdfColumn struct {
+	title string
+	share float64 // fraction of the content width
+}
+
+type tocEntry struct {
+	title string
+	level int
+	page  *pdf.Page
+}
+
+// pdfReport lays out a document top to bottom, breaking pages as needed and
+// remembering headings for the table of contents.
+type pdfReport struct {
+	files   AttachmentReader
+	doc     *pdf.Document
+	kind    string
+	title   string
+	page    *pdf.Page
+	y       float64
+	toc     []tocEntry
+	created time.Time
+}
+
+func (e *PDFExporter) newReport(kind, title string) *pdfReport {
+	r := &pdfReport{
+		files:   e.files,
+		doc:     pdf.New(kind + ": " + title),
+		kind:    kind,
+		title:   title,
+		created: time.Now(),
+	}
+	r.doc.Created = r.created
+	return r
+}
+
+// cover fills the first page with the title, key facts and a sign-off block.
+func (r *pdfReport) cover(meta [][2]string) {
+	page := r.doc.AddPage()
+
+	page.SetFillColor(pdfAccentColor)
+	page.Rect(0, 0, pdf.PageWidth, 12, true, false)
+
+	page.SetFillColor(pdfMutedColor)
+	page.Text(pdfMargin, 180, pdf.HelveticaBold, 14, strings.ToUpper(r.kind))
+
+	page.SetFillColor(pdfTextColor)
+	y := 215.0
+	for _, line := range pdf.WrapText(pdf.HelveticaBold, 28, r.title, pdfContentWidth) {
+		page.Text(pdfMargin, y, pdf.HelveticaBold, 28, line)
+		y += 34
+	}
+
+	y += 20
+	for _, field := range meta {
+		page.SetFillColor(pdfMutedColor)
+		page.Text(pdfMargin, y, pdf.HelveticaBold, 11, field[0])
+		page.SetFillColor(pdfTextColor)
+		page.Text(pdfMargin+110, y, pdf.Helvetica, 11, pdf.Truncate(pdf.Helvetica, 11, field[1], pdfContentWidth-110))
+		y += 18
+	}
+
+	// Sign-off block for reviewers
+	y = pdfBottom - 150
+	page.SetFillColor(pdfTextColor)
+	page.Text(pdfMargin, y, pdf.HelveticaBold, 12, "Sign-off")
+	page.SetStrokeColor(pdfBorderColor)
+	page.SetLineWidth(0.8)
+	for _, role := range []string{"Prepared by", "Reviewed by", "Approved by"} {
+		y += 36
+		page.SetFillColor(pdfMutedColor)
+		page.Text(pdfMargin, y, pdf.Helvetica, 10, role)
+		page.Line(pdfMargin+80, y+2, pdfMargin+300, y+2)
+		page.Text(pdfMargin+320, y, pdf.Helvetica, 10, "Date")
+		page.Line(pdfMargin+350, y+2, pdfMargin+pdfContentWidth, y+2)
+	}
+
+	page.SetFillColor(pdfMutedColor)
+	page.Text(pdfMargin, pdfBottom+20, pdf.Helvetica, 9, "Generated "+r.created.Format("2006-01-02 15:04 MST"))
+
+	r.newPage()
+}
+
+func (r *pdfReport) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = pdfMargin
+}
+
+// ensure starts a new page unless height more points fit on the current one.
+func (r *pdfReport) ensure(height float64) {
+	if r.page == nil || r.y+height > pdfBottom {
+		r.newPage()
+	}
+}
+
+func (r *pdfReport) heading(title string, level int) {
+	size := 16.0
+	if level > 1 {
+		size = 12.5
+	}
+
+	// Keep headings together with at least a couple of lines of content
+	r.ensure(size*2 + pdfBodySize*pdfLeading*3)
+	if r.y > pdfMargin {
+		r.y += size * 0.8
+	}
+
+	r.toc = append(r.toc, tocEntry{title: title, level: level, page: r.page})
+
+	r.page.SetFillColor(pdfTextColor)
+	for _, line := range pdf.WrapText(pdf.HelveticaBold, size, title, pdfContentWidth) {
+		r.y += size
+		r.page.Text(pdfMargin, r.y, pdf.HelveticaBold, size, line)
+		r.y += size * 0.35
+	}
+	if level == 1 {
+		r.page.SetStrokeColor(pdfBorderColor)
+		r.page.SetLineWidth(0.8)
+		r.page.Line(pdfMargin, r.y+2, pdfMargin+pdfContentWidth, r.y+2)
+	}
+	r.y += size * 0.6
+}
+
+func (r *pdfReport) paragraph(text string, font pdf.Font, size float64, color pdf.Color, indent float64) {
+	lineHeight := size * pdfLeading
+	for _, line := range pdf.WrapText(font, size, text, pdfContentWidth-indent) {
+		r.ensure(lineHeight)
+		r.y += lineHeight
+		r.page.SetFillColor(color)
+		r.page.Text(pdfMargin+indent, r.y-size*0.3, font, size, line)
+	}
+	r.y += size * 0.5
+}
+
+func (r *pdfReport) textSection(title, content string) {
+	if content == "" {
+		return
+	}
+	r.heading(title, 1)
+	r.paragraph(content, pdf.Helvetica, pdfBodySize, pdfTextColor, 0)
+}
+
+// labeled writes a bold caption followed by its text, skipping empty values.
+func (r *pdfReport) labeled(label, content string) {
+	if content == "" {
+		return
+	}
+	r.paragraph(label, pdf.HelveticaBold, pdfBodySize, pdfMutedColor, 0)
+	r.y -= pdfBodySize * 0.5
+	r.paragraph(content, pdf.Helvetica, pdfBodySize, pdfTextColor, 0)
+}
+
+func (r *pdfReport) fields(fields [][2]string) {
+	lineHeight := pdfBodySize * pdfLeading
+	for _, field := range fields {
+		lines := pdf.WrapText(pdf.Helvetica, pdfBodySize, field[1], pdfContentWidth-110)
+		r.ensure(lineHeight * float64(len(lines)))
+		r.page.SetFillColor(pdfMutedColor)
+		r.page.Text(pdfMargin, r.y+lineHeight-pdfBodySize*0.3, pdf.HelveticaBold, pdfBodySize, field[0])
+		r.page.SetFillColor(pdfTextColor)
+		for _, line := range lines {
+			r.y += lineHeight
+			r.page.Text(pdfMargin+110, r.y-pdfBodySize*0.3, pdf.Helvetica, pdfBodySize, line)
+		}
+	}
+	r.y += pdfBodySize * 0.5
+}
+
+func (r *pdfReport) steps(steps []TestStep) {
+	if len(steps) == 0 {
+		return
+	}
+	rows := make([][]string, len(steps))
+	for i, step := range steps {
+		rows[i] = []string{fmt.Sprint(i + 1), step.Description, step.ExpectedResult}
+	}
+	r.table([]pdfColumn{{"#", 0.06}, {"Action", 0.52}, {"Expected Result", 0.42}}, rows, nil)
+}
+
+func (r *pdfReport) checklistItems(items []ChecklistItem) {
+	if len(items) == 0 {
+		return
+	}
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = []string{fmt.Sprint(i + 1), item.Description, item.ExpectedResult, ""}
+	}
+	r.table([]pdfColumn{{"#", 0.06}, {"Item", 0.46}, {"Expected Result", 0.36}, {"Done", 0.12}}, rows, nil)
+}
+
+// table draws a bordered table with wrapped cells. The header row is repeated
+// after every page break; cellColor may override the text color of a cell.
+func (r *pdfReport) table(columns []pdfColumn, rows [][]string, cellColor func(row, col int) *pdf.Color) {
+	const (
+		size    = 9.0
+		padding = 4.0
+	)
+	lineHeight := size * 1.3
+
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = column.share * pdfContentWidth
+	}
+
+	drawRow := func(cells []string, font pdf.Font, fill *pdf.Color, colors func(col int) *pdf.Color) {
+		wrapped := make([][]string, len(cells))
+		height := 0.0
+		for i, cell := range cells {
+			wrapped[i] = pdf.WrapText(font, size, cell, widths[i]-2*padding)
+			if h := float64(len(wrapped[i]))*lineHeight + 2*padding; h > height {
+				height = h
+			}
+		}
+
+		x := pdfMargin
+		r.page.SetStrokeColor(pdfBorderColor)
+		r.page.SetLineWidth(0.6)
+		for i := range cells {
+			if fill != nil {
+				r.page.SetFillColor(*fill)
+				r.page.Rect(x, r.y, widths[i], height, true, true)
+			} else {
+				r.page.Rect(x, r.y, widths[i], height, false, true)
+			}
+
+			color := pdfTextColor
+			if colors != nil {
+				if c := colors(i); c != nil {
+					color = *c
+				}
+			}
+			r.page.SetFillColor(color)
+			for j, line := range wrapped[i] {
+				r.page.Text(x+padding, r.y+padding+float64(j+1)*lineHeight-size*0.3, font, size, line)
+			}
+			x += widths[i]
+		}
+		r.y += height
+	}
+
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.title
+	}
+	header := func() {
+		fill := pdfHeaderFill
+		drawRow(titles, pdf.HelveticaBold, &fill, nil)
+	}
+
+	r.ensure(lineHeight*3 + 4*padding)
+	header()
+	for i, row := range rows {
+		height := 0.0
+		for j, cell := range row {
+			lines := pdf.WrapText(pdf.Helvetica, size, cell, widths[j]-2*padding)
+			if h := float64(len(lines))*lineHeight + 2*padding; h > height {
+				height = h
+			}
+		}
+		if r.y+height > pdfBottom {
+			r.newPage()
+			header()
+		}
+
+		var colors func(col int) *pdf.Color
+		if cellColor != nil {
+			rowIndex := i
+			colors = func(col int) *pdf.Color { return cellColor(rowIndex, col) }
+		}
+		drawRow(row, pdf.Helvetica, nil, colors)
+	}
+	r.y += size
+}
+
+func (r *pdfReport) passRateBar(summary ResultSummary) {
+	if summary.Total == 0 {
+		return
+	}
+	const height = 14.0
+	r.ensure(height + 10)
+
+	x := pdfMargin
+	for _, segment := range []struct {
+		status string
+		count  int
+	}{
+		{TestResultStatusPass, summary.Passed},
+		{TestResultStatusFail, summary.Failed},
+		{TestResultStatusBlocked, summary.Blocked},
+		{TestResultStatusSkipped, summary.Skipped},
+		{TestResultStatusPending, summary.Pending},
+	} {
+		if segment.count == 0 {
+			continue
+		}
+		width := float64(segment.count) / float64(summary.Total) * pdfContentWidth
+		r.page.SetFillColor(pdfStatusColor(segment.status))
+		r.page.Rect(x, r.y, width, height, true, false)
+		x += width
+	}
+	r.y += height + 10
+}
+
+// images embeds the image attachments, each with its file name as caption.
+// Files that cannot be read or decoded are listed instead of failing the
+// whole report.
+func (r *pdfReport) images(attachments []Attachment, caption string) {
+	for _, attachment := range attachments {
+		if !isImage(attachment) {
+			continue
+		}
+
+		label := attachment.FileName
+		if caption != "" {
+			label = caption + " — " + attachment.FileName
+		}
+
+		img, err := r.loadImage(attachment)
+		if err != nil {
+			r.paragraph(fmt.Sprintf("[%s: image unavailable]", label), pdf.Helvetica, 9, pdfMutedColor, 0)
+			continue
+		}
+
+		width, height := img.Size()
+		w, h := float64(width), float64(height)
+		if w > pdfContentWidth {
+			h = h * pdfContentWidth / w
+			w = pdfContentWidth
+		}
+		if maxHeight := (pdfBottom - pdfMargin) * 0.6; h > maxHeight {
+			w = w * maxHeight / h
+			h = maxHeight
+		}
+
+		r.ensure(h + 24)
+		r.page.Image(img, pdfMargin, r.y, w, h)
+		r.page.SetStrokeColor(pdfBorderColor)
+		r.page.SetLineWidth(0.6)
+		r.page.Rect(pdfMargin, r.y, w, h, false, true)
+		r.y += h
+		r.paragraph(label, pdf.Helvetica, 9, pdfMutedColor, 0)
+		r.y += 6
+	}
+}
+
+func (r *pdfReport) loadImage(attachment Attachment) (*pdf.Image, error) {
+	if r.files == nil {
+		return nil, fmt.Errorf("no file storage")
+	}
+	file, err := r.files.Get(attachment.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return r.doc.AddImage(data)
+}
+
+func (r *pdfReport) activity(history []History, comments []Comment, includeHistory, includeComments bool) {
+	if includeHistory {
+		r.heading("Change History", 1)
+		if len(history) == 0 {
+			r.paragraph("No changes recorded.", pdf.Helvetica, pdfBodySize, pdfMutedColor, 0)
+		} else {
+			rows := make([][]string, len(history))
+			for i, entry := range history {
+				fields, changes := entry.ChangedFields()
+				lines := make([]string, len(fields))
+				for j, field := range fields {
+					change := changes[field]
+					lines[j] = fmt.Sprintf("%s: %s → %s", field, FormatChangeValue(change.Old), FormatChangeValue(change.New))
+				}
+				rows[i] = []string{
+					entry.ChangedAt.Format("2006-01-02 15:04"),
+					entry.Action,
+					authorEmail(entry.User),
+					strings.Join(lines, "\n"),
+				}
+			}
+			r.table([]pdfColumn{{"Date", 0.17}, {"Action", 0.11}, {"User", 0.22}, {"Changes", 0.5}}, rows, nil)
+		}
+	}
+
+	if includeComments {
+		r.heading("Comments", 1)
+		if len(comments) == 0 {
+			r.paragraph("No comments.", pdf.Helvetica, pdfBodySize, pdfMutedColor, 0)
+		}
+		for _, thread := range CommentThreads(comments) {
+			status := ""
+			if thread.Resolved {
+				status = " — resolved"
+			}
+			r.paragraph(fmt.Sprintf("%s (%s)%s", authorEmail(thread.User), thread.CreatedAt.Format("2006-01-02 15:04"), status),
+				pdf.HelveticaBold, 9, pdfMutedColor, 0)
+			r.y -= 4
+			r.paragraph(thread.Content, pdf.Helvetica, pdfBodySize, pdfTextColor, 0)
+
+			for _, reply := range thread.Replies {
+				r.paragraph(fmt.Sprintf("%s (%s)", authorEmail(reply.User), reply.CreatedAt.Format("2006-01-02 15:04")),
+					pdf.HelveticaBold, 9, pdfMutedColor, 20)
+				r.y -= 4
+				r.paragraph(reply.Content, pdf.Helvetica, pdfBodySize, pdfTextColor, 20)
+			}
+			r.y += 4
+		}
+	}
+}
+
+// finish inserts the table of contents after the cover, numbers the pages and
+// serializes the document.
+func (r *pdfReport) finish() (string, error) {
+	const (
+		entrySize   = 10.5
+		entryHeight = entrySize * 1.8
+		tocTop      = pdfMargin + 50
+	)
+
+	perPage := int(math.Floor((pdfBottom - tocTop) / entryHeight))
+	tocPages := (len(r.toc) + perPage - 1) / perPage
+	if tocPages == 0 {
+		tocPages = 1
+	}
+
+	pages := make([]*pdf.Page, tocPages)
+	for i := range pages {
+		pages[i] = r.doc.InsertPage(1 + i)
+	}
+
+	pages[0].SetFillColor(pdfTextColor)
+	pages[0].Text(pdfMargin, pdfMargin+16, pdf.HelveticaBold, 16, "Table of Contents")
+	pages[0].SetStrokeColor(pdfBorderColor)
+	pages[0].SetLineWidth(0.8)
+	pages[0].Line(pdfMargin, pdfMargin+24, pdfMargin+pdfContentWidth, pdfMargin+24)
+
+	for i, entry := range r.toc {
+		page := pages[i/perPage]
+		y := tocTop + float64(i%perPage+1)*entryHeight
+
+		indent, font := 0.0, pdf.HelveticaBold
+		if entry.level > 1 {
+			indent, font = 18, pdf.Helvetica
+		}
+
+		number := fmt.Sprint(r.doc.PageNumber(entry.page))
+		numberWidth := pdf.TextWidth(pdf.Helvetica, entrySize, number)
+		titleWidth := pdfContentWidth - indent - numberWidth - 24
+		title := pdf.Truncate(font, entrySize, entry.title, titleWidth)
+
+		page.SetFillColor(pdfTextColor)
+		page.Text(pdfMargin+indent, y, font, entrySize, title)
+		page.Text(pdfMargin+pdfContentWidth-numberWidth, y, pdf.Helvetica, entrySize, number)
+
+		// Dot leader between the title and the page number
+		dotsFrom := pdfMargin + indent + pdf.TextWidth(font, entrySize, title) + 6
+		dotsTo := pdfMargin + pdfContentWidth - numberWidth - 6
+		if dotWidth := pdf.TextWidth(pdf.Helvetica, entrySize, "."); dotsTo > dotsFrom {
+			page.SetFillColor(pdfMutedColor)
+			page.Text(dotsFrom, y, pdf.Helvetica, entrySize, strings.Repeat(".", int((dotsTo-dotsFrom)/dotWidth)))
+		}
+
+		page.Link(pdfMargin, y-entrySize, pdfContentWidth, entryHeight, entry.page)
+	}
+
+	all := r.doc.Pages()
+	for i, page := range all[1:] {
+		page.SetFillColor(pdfMutedColor)
+		page.Text(pdfMargin, pdfBottom+24, pdf.Helvetica, 8, pdf.Truncate(pdf.Helvetica, 8, r.kind+": "+r.title, pdfContentWidth-80))
+		label := fmt.Sprintf("Page %d of %d", i+2, len(all))
+		page.Text(pdfMargin+pdfContentWidth-pdf.TextWidth(pdf.Helvetica, 8, label), pdfBottom+24, pdf.Helvetica, 8, label)
+	}
+
+	data, err := r.doc.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func isImage(attachment Attachment) bool {
+	switch strings.ToLower(attachment.MimeType) {
+	case "image/png", "image/jpeg", "image/jpg", "image/gif":
+		return true
+	}
+	return false
+}
+
+func hasImages(attachments []Attachment) bool {
+	for _, attachment := range attachments {
+		if isImage(attachment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package domain
+
+// ExportFormatInfo describes a registered export format.
+type ExportFormatInfo struct {
+	Format    ExportFormat `json:"format"`
+	Name      string       `json:"name"`
+	MimeType  string       `json:"mime_type"`
+	Extension string       `json:"extension"`
+	// EntityTypes limits the format to some entity types; empty means all.
+	EntityTypes []string `json:"entity_types,omitempty"`
+}
+
+// Supports reports whether the format can export the given entity type.
+func (i ExportFormatInfo) Supports(entityType string) bool {
+	if len(i.EntityTypes) == 0 {
+		return true
+	}
+	for _, t := range i.EntityTypes {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+type registeredExporter struct {
+	info     ExportFormatInfo
+	exporter Exporter
+}
+
+// ExporterRegistry maps export formats to the exporters that produce them.
+type ExporterRegistry struct {
+	order     []ExportFormat
+	exporters map[ExportFormat]registeredExporter
+}
+
+func NewExporterRegistry() *ExporterRegistry {
+	return &ExporterRegistry{exporters: make(map[ExportFormat]registeredExporter)}
+}
+
+// Register adds an exporter for info.Format, replacing any previous one.
+// Formats are listed in registration order.
+func (r *ExporterRegistry) Register(info ExportFormatInfo, exporter Exporter) {
+	if _, ok := r.exporters[info.Format]; !ok {
+		r.order = append(r.order, info.Format)
+	}
+	r.exporters[info.Format] = registeredExporter{info: info, exporter: exporter}
+}
+
+// Get returns the exporter registered for the format.
+func (r *ExporterRegistry) Get(format ExportFormat) (Exporter, ExportFormatInfo, bool) {
+	entry, ok := r.exporters[format]
+	return entry.exporter, entry.info, ok
+}
+
+// Formats lists the registered formats in registration order.
+func (r *ExporterRegistry) Formats() []ExportFormatInfo {
+	formats := make([]ExportFormatInfo, len(r.order))
+	for i, format := range r.order {
+		formats[i] = r.exporters[format].info
+	}
+	return formats
+}
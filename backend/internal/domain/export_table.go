@@ -0,0 +1,229 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/pkg/xlsx"
+)
+
+// TestCaseListExporter is implemented by exporters that can write many test
+// cases into a single document, such as a project's whole test-case list.
+type TestCaseListExporter interface {
+	ExportTestCases(testCases []TestCase) (string, error)
+}
+
+var errTableUnsupported = errors.New("spreadsheet export is only available for test cases, checklists and test runs")
+
+// exportTable is the row-based form of an entity shared by the CSV and XLSX
+// exporters. The first row holds the column titles.
+type exportTable struct {
+	name string
+	rows [][]string
+}
+
+func tableTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// testCaseTable writes one row per step with the test case columns repeated
+// on every row. Test cases without steps get a single row.
+func testCaseTable(name string, testCases []TestCase) exportTable {
+	table := exportTable{name: name, rows: [][]string{{
+		"Test Case ID", "Title", "Description", "Pre-Steps", "Expected Result",
+		"Automation Status", "Automation IDs",
+		"Step", "Step Action", "Step Expected Result", "Created", "Last Updated",
+	}}}
+
+	for _, testCase := range testCases {
+		columns := []string{
+			testCase.ID.String(),
+			testCase.Title,
+			testCase.Description,
+			testCase.PreSteps,
+			testCase.ExpectedResult,
+			string(testCase.AutomationStatus),
+			strings.Join(testCase.AutomationKeys(), "\n"),
+		}
+		created, updated := tableTime(testCase.CreatedAt), tableTime(testCase.UpdatedAt)
+
+		if len(testCase.Steps) == 0 {
+			table.rows = append(table.rows, append(append([]string{}, columns...), "", "", "", created, updated))
+			continue
+		}
+		for i, step := range testCase.Steps {
+			row := append(append([]string{}, columns...), fmt.Sprint(i+1), step.Description, step.ExpectedResult, created, updated)
+			table.rows = append(table.rows, row)
+		}
+	}
+	return table
+}
+
+func checklistTable(checklist *Checklist) exportTable {
+	table := exportTable{name: checklist.Name, rows: [][]string{{
+		"Checklist ID", "Checklist", "Item", "Description", "Expected Result",
+	}}}
+
+	for i, item := range checklist.Items {
+		table.rows = append(table.rows, []string{
+			checklist.ID.String(),
+			checklist.Name,
+			fmt.Sprint(i + 1),
+			item.Description,
+			item.ExpectedResult,
+		})
+	}
+	return table
+}
+
+// testRunTable writes one row per test result.
+func testRunTable(testRun *TestRun) exportTable {
+	table := exportTable{name: testRun.Name, rows: [][]string{{
+		"Result ID", "Test Run", "Type", "Title", "Status", "Executed By",
+		"Executed At", "Run Started", "Run Completed", "Comments",
+	}}}
+
+	started, completed := tableTime(testRun.StartedAt), ""
+	if testRun.CompletedAt != nil {
+		completed = tableTime(*testRun.CompletedAt)
+	}
+
+	for _, result := range testRun.Results {
+		kind := ""
+		if result.TestCaseID != nil {
+			kind = "Test Case"
+		} else if result.ChecklistItemID != nil {
+			kind = "Checklist Item"
+		}
+
+		table.rows = append(table.rows, []string{
+			result.ID.String(),
+			testRun.Name,
+			kind,
+			ResultTitle(&result),
+			result.Status,
+			result.ExecutorName(),
+			tableTime(result.ExecutedAt),
+			started,
+			completed,
+			result.Comments,
+		})
+	}
+	return table
+}
+
+// CSVExporter writes entities as comma-separated values. Test plans and test
+// strategies have no tabular form and are not supported.
+type CSVExporter struct{}
+
+func NewCSVExporter() *CSVExporter {
+	return &CSVExporter{}
+}
+
+func (e *CSVExporter) ExportTestPlan(plan *TestPlan, includeHistory, includeComments bool) (string, error) {
+	return "", errTableUnsupported
+}
+
+func (e *CSVExporter) ExportTestCase(testCase *TestCase, includeHistory, includeComments bool) (string, error) {
+	return e.write(testCaseTable(testCase.Title, []TestCase{*testCase}))
+}
+
+func (e *CSVExporter) ExportTestCases(testCases []TestCase) (string, error) {
+	return e.write(testCaseTable("Test Cases", testCases))
+}
+
+func (e *CSVExporter) ExportChecklist(checklist *Checklist, includeHistory, includeComments bool) (string, error) {
+	return e.write(checklistTable(checklist))
+}
+
+func (e *CSVExporter) ExportTestStrategy(strategy *TestStrategy, includeHistory, includeComments bool) (string, error) {
+	return "", errTableUnsupported
+}
+
+func (e *CSVExporter) ExportTestRun(testRun *TestRun, includeHistory, includeComments bool) (string, error) {
+	return e.write(testRunTable(testRun))
+}
+
+func (e *CSVExporter) write(table exportTable) (string, error) {
+	var buf bytes.Buffer
+	// The byte order mark makes spreadsheet applications read the file as UTF-8
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	for _, row := range table.rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = csvCell(value)
+		}
+		if err := w.Write(cells); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// csvFormulaStart lists the characters that make spreadsheet applications
+// read a cell as a formula.
+const csvFormulaStart = "=+-@\t\r"
+
+// csvCell keeps spreadsheet applications from running user text as a formula
+// by prefixing values that would start one with an apostrophe.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaStart, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// XLSXExporter writes entities as single-sheet Excel workbooks. Test plans and
+// test strategies have no tabular form and are not supported.
+type XLSXExporter struct{}
+
+func NewXLSXExporter() *XLSXExporter {
+	return &XLSXExporter{}
+}
+
+func (e *XLSXExporter) ExportTestPlan(plan *TestPlan, includeHistory, includeComments bool) (string, error) {
+	return "", errTableUnsupported
+}
+
+func (e *XLSXExporter) ExportTestCase(testCase *TestCase, includeHistory, includeComments bool) (string, error) {
+	return e.write(testCaseTable(testCase.Title, []TestCase{*testCase}))
+}
+
+func (e *XLSXExporter) ExportTestCases(testCases []TestCase) (string, error) {
+	return e.write(testCaseTable("Test Cases", testCases))
+}
+
+func (e *XLSXExporter) ExportChecklist(checklist *Checklist, includeHistory, includeComments bool) (string, error) {
+	return e.write(checklistTable(checklist))
+}
+
+func (e *XLSXExporter) ExportTestStrategy(strategy *TestStrategy, includeHistory, includeComments bool) (string, error) {
+	return "", errTableUnsupported
+}
+
+func (e *XLSXExporter) ExportTestRun(testRun *TestRun, includeHistory, includeComments bool) (string, error) {
+	return e.write(testRunTable(testRun))
+}
+
+// write stores every cell as an inline string, which spreadsheet applications
+// never evaluate, so text starting with "=" stays text.
+func (e *XLSXExporter) write(table exportTable) (string, error) {
+	var buf bytes.Buffer
+	if err := xlsx.Write(&buf, xlsx.Sheet{Name: table.name, Rows: table.rows}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	EntityTypeTestPlan     = "test_plan"
+	EntityTypeTestCase     = "test_case"
+	EntityTypeChecklist    = "checklist"
+	EntityTypeTestStrategy = "test_strategy"
+	EntityTypeTestRun      = "test_run"
+
+	// EntityTypeTestResult is only used to resolve the project of result
+	// attachments; results have no history or comments of their own.
+	EntityTypeTestResult = "test_result"
+)
+
+// ValidEntityType reports whether entityType names one of the project-scoped entities.
+func ValidEntityType(entityType string) bool {
+	switch entityType {
+	case EntityTypeTestPlan, EntityTypeTestCase, EntityTypeChecklist, EntityTypeTestStrategy, EntityTypeTestRun:
+		return true
+	}
+	return false
+}
+
+const (
+	HistoryActionCreated  = "created"
+	HistoryActionUpdated  = "updated"
+	HistoryActionDeleted  = "deleted"
+	HistoryActionRestored = "restored"
+)
+
+// FieldChange is a single before/after pair stored in History.Changes.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Fields that change on every save or are loaded relations rather than data
+// of the entity itself.
+var diffIgnoredFields = map[string]bool{
+	"id":          true,
+	"created_at":  true,
+	"updated_at":  true,
+	"version":     true,
+	"history":     true,
+	"comments":    true,
+	"attachments": true,
+	"results":     true,
+	"executor":    true,
+	"deleted_at":  true,
+	"deleted_by":  true,
+}
+
+// Diff compares the JSON representation of two entities field by field. Either
+// side may be nil, which yields every non-empty field of the other side, so the
+// same function describes creates and deletes.
+func Diff(before, after interface{}) (map[string]FieldChange, error) {
+	oldFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, oldValue := range oldFields {
+		if diffIgnoredFields[key] {
+			continue
+		}
+		newValue, ok := newFields[key]
+		if !ok {
+			if !isEmptyValue(oldValue) {
+				changes[key] = FieldChange{Old: oldValue}
+			}
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for key, newValue := range newFields {
+		if diffIgnoredFields[key] {
+			continue
+		}
+		if _, ok := oldFields[key]; !ok && !isEmptyValue(newValue) {
+			changes[key] = FieldChange{New: newValue}
+		}
+	}
+
+	return changes, nil
+}
+
+// NestChanges prefixes every field name with prefix, for changes made to a child
+// record (a checklist item, a test result) that are recorded on the parent.
+func NestChanges(prefix string, changes map[string]FieldChange) map[string]FieldChange {
+	nested := make(map[string]FieldChange, len(changes))
+	for key, change := range changes {
+		nested[prefix+"."+key] = change
+	}
+	return nested
+}
+
+func toFieldMap(entity interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if entity == nil {
+		return fields, nil
+	}
+	if v := reflect.ValueOf(entity); v.Kind() == reflect.Ptr && v.IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == "" || v == "00000000-0000-0000-0000-000000000000" || v == "0001-01-01T00:00:00Z"
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// ChangedFields decodes the stored changes, sorted by field name.
+func (h *History) ChangedFields() ([]string, map[string]FieldChange) {
+	changes := make(map[string]FieldChange)
+	if h.Changes != "" {
+		_ = json.Unmarshal([]byte(h.Changes), &changes)
+	}
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields, changes
+}
+
+// FormatChangeValue renders a change value for human readers.
+func FormatChangeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(empty)"
+	case string:
+		if v == "" {
+			return "(empty)"
+		}
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,521 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AntVerkh/test-management-system/pkg/xlsx"
+	"github.com/google/uuid"
+)
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV      ImportFormat = "csv"
+	ImportFormatXLSX     ImportFormat = "xlsx"
+	ImportFormatTestRail ImportFormat = "testrail"
+	ImportFormatJUnit    ImportFormat = "junit"
+)
+
+// MaxTestCaseTitleLength matches the size of the test_cases.title column.
+const MaxTestCaseTitleLength = 255
+
+// ImportMapping names the spreadsheet column that holds each test case field.
+// Column titles are matched case-insensitively; an empty name leaves the
+// field unmapped.
+type ImportMapping struct {
+	// ID groups consecutive rows into one test case. Without it, rows with an
+	// empty or repeated title continue the previous test case.
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	PreSteps       string `json:"pre_steps"`
+	ExpectedResult string `json:"expected_result"`
+	StepAction     string `json:"step_action"`
+	StepExpected   string `json:"step_expected"`
+	// StepsText holds all steps of a test case as one numbered list.
+	StepsText        string `json:"steps_text"`
+	AutomationStatus string `json:"automation_status"`
+	// AutomationIDs holds identifiers separated by new lines or semicolons.
+	AutomationIDs string `json:"automation_ids"`
+}
+
+// DefaultImportMapping reads the columns written by the CSV and XLSX exporters,
+// so exported test cases can be imported again.
+var DefaultImportMapping = ImportMapping{
+	ID:               "Test Case ID",
+	Title:            "Title",
+	Description:      "Description",
+	PreSteps:         "Pre-Steps",
+	ExpectedResult:   "Expected Result",
+	StepAction:       "Step Action",
+	StepExpected:     "Step Expected Result",
+	AutomationStatus: "Automation Status",
+	AutomationIDs:    "Automation IDs",
+}
+
+// TestRailImportMapping reads TestRail CSV exports made with either the
+// "Test Case (Steps)" or the "Test Case (Text)" template.
+var TestRailImportMapping = ImportMapping{
+	ID:             "ID",
+	Title:          "Title",
+	Description:    "Section",
+	PreSteps:       "Preconditions",
+	ExpectedResult: "Expected Result",
+	StepAction:     "Steps (Step)",
+	StepExpected:   "Steps (Expected Result)",
+	StepsText:      "Steps",
+}
+
+// Merge returns m with every non-empty field of override applied.
+func (m ImportMapping) Merge(override ImportMapping) ImportMapping {
+	merge := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	merge(&m.ID, override.ID)
+	merge(&m.Title, override.Title)
+	merge(&m.Description, override.Description)
+	merge(&m.PreSteps, override.PreSteps)
+	merge(&m.ExpectedResult, override.ExpectedResult)
+	merge(&m.StepAction, override.StepAction)
+	merge(&m.StepExpected, override.StepExpected)
+	merge(&m.StepsText, override.StepsText)
+	merge(&m.AutomationStatus, override.AutomationStatus)
+	merge(&m.AutomationIDs, override.AutomationIDs)
+	return m
+}
+
+// ImportRowIssue explains why a source row was skipped or rejected. Rows are
+// numbered as a spreadsheet shows them, so the header is row 1; for JUnit
+// files the row is the position of the <testcase> element.
+type ImportRowIssue struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport summarises an import. Rows belonging to a test case with a
+// validation error are reported in Errors and nothing is created for them.
+// For a dry run the created counts describe what an import would create.
+type ImportReport struct {
+	Format           ImportFormat     `json:"format"`
+	DryRun           bool             `json:"dry_run"`
+	TotalRows        int              `json:"total_rows"`
+	CreatedRows      int              `json:"created_rows"`
+	TestCasesCreated int              `json:"test_cases_created"`
+	StepsCreated     int              `json:"steps_created"`
+	Skipped          []ImportRowIssue `json:"skipped"`
+	Errors           []ImportRowIssue `json:"errors"`
+	TestCaseIDs      []uuid.UUID      `json:"test_case_ids"`
+}
+
+// ImportedTestCase is a test case read from an import file together with the
+// source rows it was built from.
+type ImportedTestCase struct {
+	TestCase TestCase
+	Rows     []int
+}
+
+// ParseImport reads test cases from data. Valid test cases are returned in
+// file order; skipped and invalid rows are recorded in the report. An error
+// is returned only when the file as a whole cannot be read.
+func ParseImport(format ImportFormat, data []byte, mapping ImportMapping) ([]ImportedTestCase, *ImportReport, error) {
+	report := &ImportReport{Format: format, Skipped: []ImportRowIssue{}, Errors: []ImportRowIssue{}, TestCaseIDs: []uuid.UUID{}}
+
+	var rows [][]string
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		rows, err = readImportCSV(data)
+		unescapeCSVCells(rows)
+		mapping = DefaultImportMapping.Merge(mapping)
+	case ImportFormatTestRail:
+		rows, err = readImportCSV(data)
+		mapping = TestRailImportMapping.Merge(mapping)
+	case ImportFormatXLSX:
+		rows, err = xlsx.ReadFirstSheet(data)
+		mapping = DefaultImportMapping.Merge(mapping)
+	case ImportFormatJUnit:
+		testCases, err := parseJUnitImport(data, report)
+		return testCases, report, err
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format: %s", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	testCases, err := parseImportRows(rows, mapping, report)
+	return testCases, report, err
+}
+
+func readImportCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		return nil, errors.New("CSV file must be UTF-8 encoded")
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	return rows, nil
+}
+
+// unescapeCSVCells removes the apostrophe the CSV exporter puts in front of
+// cells that would otherwise start a formula.
+func unescapeCSVCells(rows [][]string) {
+	for _, row := range rows {
+		for i, value := range row {
+			if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaStart, rune(value[1])) {
+				row[i] = value[1:]
+			}
+		}
+	}
+}
+
+// importColumns resolves mapped column titles to positions in the header row.
+type importColumns map[string]int
+
+func (c importColumns) get(row []string, field string) string {
+	index, ok := c[field]
+	if !ok || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
+
+func resolveImportColumns(header []string, mapping ImportMapping) (importColumns, error) {
+	positions := make(map[string]int, len(header))
+	for i, title := range header {
+		title = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(title, "\ufeff")))
+		if _, ok := positions[title]; !ok {
+			positions[title] = i
+		}
+	}
+
+	columns := make(importColumns)
+	for field, title := range map[string]string{
+		"id":                mapping.ID,
+		"title":             mapping.Title,
+		"description":       mapping.Description,
+		"pre_steps":         mapping.PreSteps,
+		"expected_result":   mapping.ExpectedResult,
+		"step_action":       mapping.StepAction,
+		"step_expected":     mapping.StepExpected,
+		"steps_text":        mapping.StepsText,
+		"automation_status": mapping.AutomationStatus,
+		"automation_ids":    mapping.AutomationIDs,
+	} {
+		if title == "" {
+			continue
+		}
+		if index, ok := positions[strings.ToLower(strings.TrimSpace(title))]; ok {
+			columns[field] = index
+		}
+	}
+
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("title column %q not found in header row", mapping.Title)
+	}
+	return columns, nil
+}
+
+// importGroup collects the rows of one test case while it is being read.
+type importGroup struct {
+	key      string
+	title    string
+	testCase TestCase
+	rows     []int
+	issues   []ImportRowIssue
+}
+
+func parseImportRows(rows [][]string, mapping ImportMapping, report *ImportReport) ([]ImportedTestCase, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("import file is empty")
+	}
+	columns, err := resolveImportColumns(rows[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var testCases []ImportedTestCase
+	var current *importGroup
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if len(current.issues) > 0 {
+			report.Errors = append(report.Errors, current.issues...)
+			for _, row := range current.rows {
+				if !hasImportIssue(current.issues, row) {
+					report.Errors = append(report.Errors, ImportRowIssue{
+						Row:     row,
+						Message: fmt.Sprintf("test case starting on row %d has validation errors", current.rows[0]),
+					})
+				}
+			}
+		} else {
+			testCases = append(testCases, ImportedTestCase{TestCase: current.testCase, Rows: current.rows})
+		}
+		current = nil
+	}
+
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		report.TotalRows++
+
+		if isBlankRow(row) {
+			report.Skipped = append(report.Skipped, ImportRowIssue{Row: rowNumber, Message: "empty row"})
+			continue
+		}
+
+		key, title := columns.get(row, "id"), columns.get(row, "title")
+		continues := current != nil &&
+			(key != "" && key == current.key ||
+				key == "" && (title == "" || title == current.title))
+
+		if !continues {
+			flush()
+			current = &importGroup{key: key, title: title}
+			current.testCase = TestCase{
+				Title:          title,
+				Description:    columns.get(row, "description"),
+				PreSteps:       columns.get(row, "pre_steps"),
+				ExpectedResult: columns.get(row, "expected_result"),
+			}
+
+			if title == "" {
+				current.issues = append(current.issues, ImportRowIssue{Row: rowNumber, Field: "title", Message: "title is required"})
+			} else if utf8.RuneCountInString(title) > MaxTestCaseTitleLength {
+				current.issues = append(current.issues, ImportRowIssue{
+					Row:     rowNumber,
+					Field:   "title",
+					Message: fmt.Sprintf("title must be at most %d characters", MaxTestCaseTitleLength),
+				})
+			}
+
+			current.testCase.SetAutomationKeys(splitAutomationIDs(columns.get(row, "automation_ids")))
+			for _, key := range current.testCase.AutomationKeys() {
+				if utf8.RuneCountInString(key) > MaxAutomationKeyLength {
+					current.issues = append(current.issues, ImportRowIssue{
+						Row:     rowNumber,
+						Field:   "automation_ids",
+						Message: fmt.Sprintf("automation identifiers must be at most %d characters", MaxAutomationKeyLength),
+					})
+					break
+				}
+			}
+
+			status, ok := parseAutomationStatus(columns.get(row, "automation_status"), len(current.testCase.AutomationIDs) > 0)
+			if !ok {
+				current.issues = append(current.issues, ImportRowIssue{
+					Row:     rowNumber,
+					Field:   "automation_status",
+					Message: "automation status must be manual, automated or to be automated",
+				})
+			}
+			current.testCase.AutomationStatus = status
+
+			for _, action := range splitStepsText(columns.get(row, "steps_text")) {
+				current.testCase.Steps = append(current.testCase.Steps, TestStep{Description: action})
+			}
+		}
+		current.rows = append(current.rows, rowNumber)
+
+		action, expected := columns.get(row, "step_action"), columns.get(row, "step_expected")
+		switch {
+		case action != "":
+			current.testCase.Steps = append(current.testCase.Steps, TestStep{Description: action, ExpectedResult: expected})
+		case expected != "":
+			current.issues = append(current.issues, ImportRowIssue{
+				Row:     rowNumber,
+				Field:   "step_action",
+				Message: "step action is required when a step expected result is given",
+			})
+		case continues:
+			report.Skipped = append(report.Skipped, ImportRowIssue{Row: rowNumber, Message: "row has no step data"})
+			current.rows = current.rows[:len(current.rows)-1]
+		}
+	}
+	flush()
+
+	for i := range testCases {
+		for j := range testCases[i].TestCase.Steps {
+			testCases[i].TestCase.Steps[j].Order = j + 1
+		}
+	}
+	return testCases, nil
+}
+
+// parseAutomationStatus accepts the status names with spaces, dashes or
+// underscores in any case. An empty status means automated when the test
+// case has identifiers and manual otherwise.
+func parseAutomationStatus(value string, hasIDs bool) (AutomationStatus, bool) {
+	if value == "" {
+		if hasIDs {
+			return AutomationStatusAutomated, true
+		}
+		return AutomationStatusManual, true
+	}
+
+	status := AutomationStatus(strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(value)))
+	return status, ValidAutomationStatus(status)
+}
+
+func splitAutomationIDs(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ';'
+	})
+}
+
+var stepNumberPattern = regexp.MustCompile(`^\s*\d+[.)]\s+`)
+
+// splitStepsText splits a numbered list ("1. Open\n2. Save") into steps.
+// Lines without a number continue the previous step; text without any
+// numbering is a single step.
+func splitStepsText(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	var steps []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if stepNumberPattern.MatchString(line) || len(steps) == 0 {
+			steps = append(steps, strings.TrimSpace(stepNumberPattern.ReplaceAllString(line, "")))
+			continue
+		}
+		steps[len(steps)-1] = strings.TrimSpace(steps[len(steps)-1] + "\n" + line)
+	}
+
+	result := steps[:0]
+	for _, step := range steps {
+		if step != "" {
+			result = append(result, step)
+		}
+	}
+	return result
+}
+
+func isBlankRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func hasImportIssue(issues []ImportRowIssue, row int) bool {
+	for _, issue := range issues {
+		if issue.Row == row {
+			return true
+		}
+	}
+	return false
+}
+
+type junitImportSuite struct {
+	Name      string             `xml:"name,attr"`
+	Suites    []junitImportSuite `xml:"testsuite"`
+	TestCases []struct {
+		Name      string `xml:"name,attr"`
+		ClassName string `xml:"classname,attr"`
+	} `xml:"testcase"`
+}
+
+// parseJUnitImport creates one test case per <testcase> element, keyed like
+// the results of a JUnit CI report. Nested suites are flattened and repeated
+// class and name pairs are skipped.
+func parseJUnitImport(data []byte, report *ImportReport) ([]ImportedTestCase, error) {
+	var root junitImportSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JUnit XML: %w", err)
+	}
+
+	var testCases []ImportedTestCase
+	seen := make(map[string]int)
+	position := 0
+
+	var walk func(suite junitImportSuite)
+	walk = func(suite junitImportSuite) {
+		for _, tc := range suite.TestCases {
+			position++
+			report.TotalRows++
+			name := strings.TrimSpace(tc.Name)
+
+			if name == "" {
+				report.Errors = append(report.Errors, ImportRowIssue{Row: position, Field: "title", Message: "testcase has no name"})
+				continue
+			}
+			if utf8.RuneCountInString(name) > MaxTestCaseTitleLength {
+				report.Errors = append(report.Errors, ImportRowIssue{
+					Row:     position,
+					Field:   "title",
+					Message: fmt.Sprintf("title must be at most %d characters", MaxTestCaseTitleLength),
+				})
+				continue
+			}
+
+			className := strings.TrimSpace(tc.ClassName)
+			if className == "" {
+				className = suite.Name
+			}
+			key := automationKey(className, name)
+			if utf8.RuneCountInString(key) > MaxAutomationKeyLength {
+				report.Errors = append(report.Errors, ImportRowIssue{
+					Row:     position,
+					Field:   "automation_ids",
+					Message: fmt.Sprintf("automation key must be at most %d characters", MaxAutomationKeyLength),
+				})
+				continue
+			}
+			if first, ok := seen[key]; ok {
+				report.Skipped = append(report.Skipped, ImportRowIssue{
+					Row:     position,
+					Message: fmt.Sprintf("duplicate of testcase %d", first),
+				})
+				continue
+			}
+			seen[key] = position
+
+			description := ""
+			if tc.ClassName != "" {
+				description = "Class: " + tc.ClassName
+			}
+			if suite.Name != "" {
+				description = joinLines(description, "Suite: "+suite.Name)
+			}
+
+			testCases = append(testCases, ImportedTestCase{
+				TestCase: TestCase{
+					Title:            name,
+					Description:      description,
+					AutomationStatus: AutomationStatusAutomated,
+					AutomationIDs:    []TestCaseAutomationID{{ID: uuid.New(), Key: key}},
+				},
+				Rows: []int{position},
+			})
+		}
+		for _, child := range suite.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	if report.TotalRows == 0 {
+		return nil, errors.New("JUnit file contains no test cases")
+	}
+	return testCases, nil
+}
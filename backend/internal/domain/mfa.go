@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAEnrollment holds a user's TOTP secret. It only takes effect once it is
+// confirmed with a first code; until then enrolling again replaces it.
+type MFAEnrollment struct {
+	UserID      uuid.UUID  `gorm:"type:uuid;primary_key" json:"user_id"`
+	Secret      string     `gorm:"not null" json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep is the time step of the last accepted code, so each code works once
+	LastUsedStep   int64      `gorm:"not null;default:0" json:"-"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Confirmed reports whether the enrollment is in effect.
+func (e *MFAEnrollment) Confirmed() bool {
+	return e.ConfirmedAt != nil
+}
+
+// MFARecoveryCode is a one-time code that stands in for an authenticator code
+// when the device is lost. Only its hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFAPolicy makes a second factor mandatory for every user with the role.
+// Roles without a policy row do not require one.
+type MFAPolicy struct {
+	Role      UserRole   `gorm:"type:varchar(20);primary_key" json:"role"`
+	Required  bool       `gorm:"not null;default:false" json:"required"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// MFAStatus describes a user's two-factor setup.
+type MFAStatus struct {
+	Enabled     bool       `json:"enabled"`
+	Required    bool       `json:"required"` // by the policy for the user's role
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// RecoveryCodesRemaining lets clients suggest generating new codes before they run out
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// MFASetup is what an authenticator app needs to start producing codes.
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth URI, usually shown as a QR code
+}
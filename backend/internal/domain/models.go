@@ -1,10 +1,13 @@
 package domain
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	_ "gorm.io/gorm"
+	"gorm.io/gorm"
 )
 
 type UserRole string
@@ -16,12 +19,39 @@ const (
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	Email     string    `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string    `gorm:"not null" json:"-"`
-	Role      UserRole  `gorm:"type:varchar(20);not null" json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Email            string     `gorm:"uniqueIndex;not null" json:"email"`
+	Password         string     `gorm:"not null" json:"-"`
+	Role             UserRole   `gorm:"type:varchar(20);not null" json:"role"`
+	IsServiceAccount bool       `gorm:"not null;default:false" json:"is_service_account"` // signs in with API tokens only
+	EmailVerifiedAt  *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleEditor ProjectRole = "editor"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+var projectRoleRank = map[ProjectRole]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleEditor: 2,
+	ProjectRoleOwner:  3,
+}
+
+// Valid reports whether r is one of the known project roles.
+func (r ProjectRole) Valid() bool {
+	_, ok := projectRoleRank[r]
+	return ok
+}
+
+// Includes reports whether r grants at least the permissions of required.
+func (r ProjectRole) Includes(required ProjectRole) bool {
+	return r.Valid() && projectRoleRank[r] >= projectRoleRank[required]
 }
 
 type Project struct {
@@ -30,6 +60,24 @@ type Project struct {
 	Description string    `json:"description"`
 	CreatedBy   uuid.UUID `gorm:"type:uuid" json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	Members []ProjectMember `gorm:"foreignKey:ProjectID" json:"members,omitempty"`
+}
+
+type ProjectMember struct {
+	ProjectID uuid.UUID   `gorm:"type:uuid;primary_key" json:"project_id"`
+	UserID    uuid.UUID   `gorm:"type:uuid;primary_key" json:"user_id"`
+	Role      ProjectRole `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// SoftDelete marks an entity as moved to the trash. GORM leaves soft deleted
+// rows out of queries unless they are made with Unscoped.
+type SoftDelete struct {
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	DeletedBy *uuid.UUID     `gorm:"type:uuid" json:"deleted_by,omitempty"`
 }
 
 type TestPlan struct {
@@ -42,6 +90,8 @@ type TestPlan struct {
 	CreatedBy   uuid.UUID `gorm:"type:uuid" json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `gorm:"not null;default:1" json:"version"`
+	SoftDelete
 
 	Checklists []Checklist `gorm:"many2many:test_plan_checklists;" json:"checklists,omitempty"`
 	TestCases  []TestCase  `gorm:"many2many:test_plan_cases;" json:"test_cases,omitempty"`
@@ -50,19 +100,48 @@ type TestPlan struct {
 }
 
 type TestStrategy struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	ProjectID   uuid.UUID `gorm:"type:uuid;not null" json:"project_id"`
-	Name        string    `gorm:"not null" json:"name"`
-	Description string    `json:"description"`
-	Content     string    `gorm:"type:text" json:"content"`
-	CreatedBy   uuid.UUID `gorm:"type:uuid" json:"created_by"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID            `gorm:"type:uuid;primary_key" json:"id"`
+	ProjectID   uuid.UUID            `gorm:"type:uuid;not null" json:"project_id"`
+	Name        string               `gorm:"not null" json:"name"`
+	Description string               `json:"description"`
+	Sections    TestStrategySections `gorm:"embedded" json:"sections"`
+	Content     string               `gorm:"type:text" json:"content"` // free-form notes outside the named sections
+	CreatedBy   uuid.UUID            `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Version     int64                `gorm:"not null;default:1" json:"version"`
+	SoftDelete
 
 	History  []History `gorm:"foreignKey:EntityID" json:"history,omitempty"`
 	Comments []Comment `gorm:"foreignKey:EntityID" json:"comments,omitempty"`
 }
 
+type TestStrategySections struct {
+	Scope         string `gorm:"type:text" json:"scope"`
+	Approach      string `gorm:"type:text" json:"approach"`
+	Environments  string `gorm:"type:text" json:"environments"`
+	Risks         string `gorm:"type:text" json:"risks"`
+	EntryCriteria string `gorm:"type:text" json:"entry_criteria"`
+	ExitCriteria  string `gorm:"type:text" json:"exit_criteria"`
+}
+
+type StrategySection struct {
+	Title   string
+	Content string
+}
+
+// Ordered returns the sections in document order, including empty ones.
+func (s TestStrategySections) Ordered() []StrategySection {
+	return []StrategySection{
+		{Title: "Scope", Content: s.Scope},
+		{Title: "Approach", Content: s.Approach},
+		{Title: "Environments", Content: s.Environments},
+		{Title: "Risks", Content: s.Risks},
+		{Title: "Entry Criteria", Content: s.EntryCriteria},
+		{Title: "Exit Criteria", Content: s.ExitCriteria},
+	}
+}
+
 type Checklist struct {
 	ID          uuid.UUID       `gorm:"type:uuid;primary_key" json:"id"`
 	ProjectID   uuid.UUID       `gorm:"type:uuid;not null" json:"project_id"`
@@ -72,6 +151,8 @@ type Checklist struct {
 	CreatedBy   uuid.UUID       `gorm:"type:uuid" json:"created_by"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
+	Version     int64           `gorm:"not null;default:1" json:"version"`
+	SoftDelete
 
 	History  []History `gorm:"foreignKey:EntityID" json:"history,omitempty"`
 	Comments []Comment `gorm:"foreignKey:EntityID" json:"comments,omitempty"`
@@ -86,20 +167,119 @@ type ChecklistItem struct {
 }
 
 type TestCase struct {
-	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
-	ProjectID      uuid.UUID  `gorm:"type:uuid;not null" json:"project_id"`
-	Title          string     `gorm:"not null" json:"title"`
-	Description    string     `json:"description"`
-	PreSteps       string     `gorm:"type:text" json:"pre_steps"`
-	Steps          []TestStep `gorm:"foreignKey:TestCaseID" json:"steps"`
-	ExpectedResult string     `gorm:"type:text" json:"expected_result"`
-	CreatedBy      uuid.UUID  `gorm:"type:uuid" json:"created_by"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-
-	Attachments []Attachment `gorm:"foreignKey:TestCaseID" json:"attachments,omitempty"`
-	History     []History    `gorm:"foreignKey:EntityID" json:"history,omitempty"`
-	Comments    []Comment    `gorm:"foreignKey:EntityID" json:"comments,omitempty"`
+	ID               uuid.UUID        `gorm:"type:uuid;primary_key" json:"id"`
+	ProjectID        uuid.UUID        `gorm:"type:uuid;not null" json:"project_id"`
+	Title            string           `gorm:"not null" json:"title"`
+	Description      string           `json:"description"`
+	PreSteps         string           `gorm:"type:text" json:"pre_steps"`
+	Steps            []TestStep       `gorm:"foreignKey:TestCaseID" json:"steps"`
+	ExpectedResult   string           `gorm:"type:text" json:"expected_result"`
+	AutomationStatus AutomationStatus `gorm:"type:varchar(32);not null;default:manual" json:"automation_status"`
+	CreatedBy        uuid.UUID        `gorm:"type:uuid" json:"created_by"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	Version          int64            `gorm:"not null;default:1" json:"version"`
+	SoftDelete
+
+	AutomationIDs []TestCaseAutomationID `gorm:"foreignKey:TestCaseID" json:"automation_ids"`
+	Attachments   []Attachment           `gorm:"foreignKey:TestCaseID" json:"attachments,omitempty"`
+	History       []History              `gorm:"foreignKey:EntityID" json:"history,omitempty"`
+	Comments      []Comment              `gorm:"foreignKey:EntityID" json:"comments,omitempty"`
+}
+
+type AutomationStatus string
+
+const (
+	AutomationStatusManual        AutomationStatus = "manual"
+	AutomationStatusAutomated     AutomationStatus = "automated"
+	AutomationStatusToBeAutomated AutomationStatus = "to_be_automated"
+)
+
+// ValidAutomationStatus reports whether status is one of the automation statuses.
+func ValidAutomationStatus(status AutomationStatus) bool {
+	switch status {
+	case AutomationStatusManual, AutomationStatusAutomated, AutomationStatusToBeAutomated:
+		return true
+	}
+	return false
+}
+
+// TestCaseAutomationID names an automated test implementing a test case, such
+// as "package#TestName" for Go or "classname#name" for JUnit. Identifiers are
+// unique within a project, so CI results map to a single test case. In JSON
+// an identifier is just its key.
+type TestCaseAutomationID struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	TestCaseID uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProjectID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_test_case_automation_ids_key"`
+	Key        string    `gorm:"size:512;not null;uniqueIndex:idx_test_case_automation_ids_key"`
+	CreatedAt  time.Time
+}
+
+func (a TestCaseAutomationID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Key)
+}
+
+func (a *TestCaseAutomationID) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &a.Key)
+}
+
+// AutomationKeys returns the keys of the test case's automation identifiers.
+func (tc *TestCase) AutomationKeys() []string {
+	keys := make([]string, len(tc.AutomationIDs))
+	for i, id := range tc.AutomationIDs {
+		keys[i] = id.Key
+	}
+	return keys
+}
+
+// SetAutomationKeys replaces the automation identifiers of the test case.
+// Blank and repeated keys are dropped; identifiers whose key is kept retain
+// their ID so updates only touch what changed.
+func (tc *TestCase) SetAutomationKeys(keys []string) {
+	existing := make(map[string]TestCaseAutomationID, len(tc.AutomationIDs))
+	for _, id := range tc.AutomationIDs {
+		existing[id.Key] = id
+	}
+
+	tc.AutomationIDs = nil
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		id, ok := existing[key]
+		if !ok {
+			id = TestCaseAutomationID{ID: uuid.New(), Key: key, CreatedAt: time.Now()}
+		}
+		id.TestCaseID = tc.ID
+		id.ProjectID = tc.ProjectID
+		tc.AutomationIDs = append(tc.AutomationIDs, id)
+	}
+}
+
+// TestCaseFilter narrows a test case listing; zero values match everything.
+type TestCaseFilter struct {
+	AutomationStatus AutomationStatus
+	// AutomationID matches test cases with an identifier containing it,
+	// ignoring case.
+	AutomationID string
+}
+
+// AutomationCoverage counts the test cases of a project by automation status.
+type AutomationCoverage struct {
+	ProjectID     uuid.UUID `json:"project_id"`
+	Total         int64     `json:"total"`
+	Manual        int64     `json:"manual"`
+	Automated     int64     `json:"automated"`
+	ToBeAutomated int64     `json:"to_be_automated"`
+	// AutomatedWithoutIDs counts automated test cases that CI results cannot
+	// be matched to because they have no automation identifier.
+	AutomatedWithoutIDs int64   `json:"automated_without_ids"`
+	AutomatedPercent    float64 `json:"automated_percent"`
 }
 
 type TestStep struct {
@@ -111,6 +291,17 @@ type TestStep struct {
 	CreatedAt      time.Time `json:"created_at"`
 }
 
+// NumberSteps sorts steps by their Order, keeping the given order for equal
+// values, and renumbers them 1..n so positions stay contiguous.
+func NumberSteps(steps []TestStep) {
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Order < steps[j].Order
+	})
+	for i := range steps {
+		steps[i].Order = i + 1
+	}
+}
+
 type TestRun struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
 	TestPlanID  uuid.UUID  `gorm:"type:uuid;not null" json:"test_plan_id"`
@@ -118,53 +309,66 @@ type TestRun struct {
 	StartedBy   uuid.UUID  `gorm:"type:uuid" json:"started_by"`
 	StartedAt   time.Time  `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	SoftDelete
 
 	Results  []TestResult `gorm:"foreignKey:TestRunID" json:"results"`
 	History  []History    `gorm:"foreignKey:EntityID" json:"history,omitempty"`
 	Comments []Comment    `gorm:"foreignKey:EntityID" json:"comments,omitempty"`
 }
 
+const (
+	TestResultStatusPending = "pending"
+	TestResultStatusPass    = "pass"
+	TestResultStatusFail    = "fail"
+	TestResultStatusBlocked = "blocked"
+	TestResultStatusSkipped = "skipped"
+)
+
 type TestResult struct {
 	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
 	TestRunID       uuid.UUID  `gorm:"type:uuid;not null" json:"test_run_id"`
 	TestCaseID      *uuid.UUID `gorm:"type:uuid" json:"test_case_id,omitempty"`
 	ChecklistItemID *uuid.UUID `gorm:"type:uuid" json:"checklist_item_id,omitempty"`
-	Status          string     `gorm:"not null" json:"status"` // pass, fail, blocked, skipped
+	Status          string     `gorm:"not null" json:"status"` // pending, pass, fail, blocked, skipped
 	Comments        string     `json:"comments"`
-	ExecutedBy      uuid.UUID  `gorm:"type:uuid" json:"executed_by"`
+	ExecutedBy      *uuid.UUID `gorm:"type:uuid" json:"executed_by,omitempty"` // nil until the result is recorded
 	ExecutedAt      time.Time  `json:"executed_at"`
 
 	TestCase      *TestCase      `gorm:"foreignKey:TestCaseID" json:"test_case,omitempty"`
 	ChecklistItem *ChecklistItem `gorm:"foreignKey:ChecklistItemID" json:"checklist_item,omitempty"`
+	Executor      *User          `gorm:"foreignKey:ExecutedBy" json:"executor,omitempty"`
+	Attachments   []Attachment   `gorm:"foreignKey:TestResultID" json:"attachments,omitempty"`
 }
 
-type Attachment struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	TestCaseID uuid.UUID `gorm:"type:uuid;not null" json:"test_case_id"`
-	FileName   string    `gorm:"not null" json:"file_name"`
-	FilePath   string    `gorm:"not null" json:"file_path"`
-	FileSize   int64     `json:"file_size"`
-	MimeType   string    `json:"mime_type"`
-	UploadedBy uuid.UUID `gorm:"type:uuid" json:"uploaded_by"`
-	UploadedAt time.Time `json:"uploaded_at"`
+// ExecutorName returns the email of the user who recorded the result, falling
+// back to their ID when the user was not loaded.
+func (r *TestResult) ExecutorName() string {
+	if r.Executor != nil && r.Executor.Email != "" {
+		return r.Executor.Email
+	}
+	if r.ExecutedBy != nil {
+		return r.ExecutedBy.String()
+	}
+	return ""
 }
 
-type Comment struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
-	EntityID   uuid.UUID `gorm:"type:uuid;not null" json:"entity_id"`
-	EntityType string    `gorm:"not null" json:"entity_type"` // test_plan, test_case, etc.
-	Content    string    `gorm:"type:text;not null" json:"content"`
-	CreatedBy  uuid.UUID `gorm:"type:uuid" json:"created_by"`
-	CreatedAt  time.Time `json:"created_at"`
-
-	User User `gorm:"foreignKey:CreatedBy" json:"user,omitempty"`
+type Attachment struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	TestCaseID   *uuid.UUID `gorm:"type:uuid;index" json:"test_case_id,omitempty"`
+	TestResultID *uuid.UUID `gorm:"type:uuid;index" json:"test_result_id,omitempty"`
+	FileName     string     `gorm:"not null" json:"file_name"`
+	FilePath     string     `gorm:"not null" json:"-"`
+	FileSize     int64      `json:"file_size"`
+	MimeType     string     `json:"mime_type"`
+	UploadedBy   uuid.UUID  `gorm:"type:uuid" json:"uploaded_by"`
+	UploadedAt   time.Time  `json:"uploaded_at"`
 }
 
 type History struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
 	EntityID   uuid.UUID `gorm:"type:uuid;not null" json:"entity_id"`
 	EntityType string    `gorm:"not null" json:"entity_type"`
-	Action     string    `gorm:"not null" json:"action"` // created, updated, deleted
+	Action     string    `gorm:"not null" json:"action"` // created, updated, deleted, restored
 	Changes    string    `gorm:"type:jsonb" json:"changes"`
 	ChangedBy  uuid.UUID `gorm:"type:uuid" json:"changed_by"`
 	ChangedAt  time.Time `json:"changed_at"`
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a signed-in client. It stores the hash of its current refresh
+// token, which is replaced on every refresh, and access tokens name the session
+// they were issued for so they stop being accepted once it is revoked.
+type Session struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID            uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	PreviousTokenHash string     `gorm:"index" json:"-"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Active reports whether the session can still be used at the given time.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AuthTokens is the token pair handed out on login and refresh.
+type AuthTokens struct {
+	AccessToken string `json:"token"`
+	// AccessTokenExpiresAt tells clients when to refresh
+	AccessTokenExpiresAt time.Time `json:"expires_at"`
+	RefreshToken         string    `json:"refresh_token"`
+}
+
+// LoginResult is the outcome of a login step. It carries either a token pair
+// and the user, or, when a second factor is needed, a short-lived MFA token
+// to complete the login with.
+type LoginResult struct {
+	*AuthTokens
+	User        *User  `json:"user,omitempty"`
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAEnrollmentRequired means the user's role requires a second factor
+	// that has not been set up yet; the MFA token is used to enroll.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+	// RecoveryCodes are returned once, when the login completed an enrollment
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// SSOInfo tells clients whether to offer single sign-on.
+type SSOInfo struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"provider_name,omitempty"`
+}
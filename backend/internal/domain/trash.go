@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashEntityTypes lists the entity types that are soft deleted into the
+// project trash.
+var TrashEntityTypes = []string{
+	EntityTypeTestPlan,
+	EntityTypeTestCase,
+	EntityTypeChecklist,
+	EntityTypeTestStrategy,
+	EntityTypeTestRun,
+}
+
+// TrashItem is a soft deleted entity waiting in the trash of its project.
+// PurgeAt is the earliest time an admin purge removes it for good.
+type TrashItem struct {
+	EntityType string     `json:"entity_type"`
+	ID         uuid.UUID  `json:"id"`
+	ProjectID  uuid.UUID  `json:"project_id"`
+	Name       string     `json:"name"`
+	DeletedAt  time.Time  `json:"deleted_at"`
+	DeletedBy  *uuid.UUID `json:"deleted_by,omitempty"`
+	PurgeAt    time.Time  `json:"purge_at"`
+}
+
+// PurgeReport counts what a trash purge removed. Test cases and checklists
+// still referenced by test results of runs that are kept stay in the trash,
+// so run history never loses what was executed; Kept counts them.
+type PurgeReport struct {
+	DeletedBefore  time.Time `json:"deleted_before"`
+	TestPlans      int64     `json:"test_plans"`
+	TestCases      int64     `json:"test_cases"`
+	Checklists     int64     `json:"checklists"`
+	TestStrategies int64     `json:"test_strategies"`
+	TestRuns       int64     `json:"test_runs"`
+	Kept           int64     `json:"kept"`
+	// FilePaths are the stored files of purged attachments, for the caller to
+	// remove once the purge is committed.
+	FilePaths []string `json:"-"`
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APITokenHandler struct {
+	tokenService service.APITokenService
+}
+
+func NewAPITokenHandler(tokenService service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{tokenService: tokenService}
+}
+
+type CreateAPITokenRequest struct {
+	Name          string              `json:"name" binding:"required"`
+	Scopes        []domain.TokenScope `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int                 `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // defaults to 90
+}
+
+// CreateAPITokenResponse is the only response that contains the token itself.
+type CreateAPITokenResponse struct {
+	*domain.APIToken
+	Token string `json:"token"`
+}
+
+// ListTokens lists the caller's personal access tokens, including revoked and expired ones.
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	createAPIToken(c, h.tokenService, userID.(uuid.UUID))
+}
+
+// RevokeToken revokes one of the caller's own tokens.
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "token")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	token, err := h.tokenService.GetToken(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if token.UserID != userID.(uuid.UUID) {
+		respondError(c, domain.NotFound("token"))
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
+
+// ListAllTokens lists the tokens of every user, or of the user given by user_id.
+func (h *APITokenHandler) ListAllTokens(c *gin.Context) {
+	userID := uuid.Nil
+	if value := c.Query("user_id"); value != "" {
+		var err error
+		if userID, err = uuid.Parse(value); err != nil {
+			respondInvalidID(c, "user")
+			return
+		}
+	}
+
+	tokens, err := h.tokenService.ListTokens(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// AdminRevokeToken revokes any user's token.
+func (h *APITokenHandler) AdminRevokeToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "token")
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
+
+// createAPIToken binds a CreateAPITokenRequest and creates the token for userID.
+func createAPIToken(c *gin.Context, tokenService service.APITokenService, userID uuid.UUID) {
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	createdBy, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	token := &domain.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedBy: createdBy.(uuid.UUID),
+	}
+
+	plain, err := tokenService.CreateToken(c.Request.Context(), token, time.Duration(req.ExpiresInDays)*24*time.Hour)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPITokenResponse{
+		APIToken: token,
+		Token:    plain,
+	})
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AttachmentHandler struct {
+	attachmentService service.AttachmentService
+	projectService    service.ProjectService
+	maxUploadSize     int64
+}
+
+func NewAttachmentHandler(attachmentService service.AttachmentService, projectService service.ProjectService, maxUploadSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		projectService:    projectService,
+		maxUploadSize:     maxUploadSize,
+	}
+}
+
+func (h *AttachmentHandler) UploadTestCaseAttachment(c *gin.Context) {
+	testCaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	if !requireEntityRole(c, h.projectService, domain.EntityTypeTestCase, testCaseID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	h.upload(c, &domain.Attachment{TestCaseID: &testCaseID})
+}
+
+func (h *AttachmentHandler) UploadTestResultAttachment(c *gin.Context) {
+	resultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test result")
+		return
+	}
+
+	if !requireEntityRole(c, h.projectService, domain.EntityTypeTestResult, resultID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	h.upload(c, &domain.Attachment{TestResultID: &resultID})
+}
+
+func (h *AttachmentHandler) upload(c *gin.Context, attachment *domain.Attachment) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	// Leave room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, errFileRequired)
+		return
+	}
+	if fileHeader.Size > h.maxUploadSize {
+		respondStatus(c, http.StatusRequestEntityTooLarge, "file_too_large", "file is too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondStatus(c, http.StatusBadRequest, "invalid_file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	attachment.FileName = fileHeader.Filename
+	attachment.FileSize = fileHeader.Size
+	attachment.MimeType = fileHeader.Header.Get("Content-Type")
+	attachment.UploadedBy = userID.(uuid.UUID)
+
+	if err := h.attachmentService.Upload(c.Request.Context(), attachment, file); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "attachment")
+		return
+	}
+
+	if _, ok := h.requireAttachmentRole(c, id, domain.ProjectRoleViewer); !ok {
+		return
+	}
+
+	attachment, file, err := h.attachmentService.Open(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	mimeType := attachment.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	c.DataFromReader(http.StatusOK, attachment.FileSize, mimeType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "attachment")
+		return
+	}
+
+	if _, ok := h.requireAttachmentRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// requireAttachmentRole loads the attachment and checks the caller's role in
+// the project of the test case or test result it belongs to.
+func (h *AttachmentHandler) requireAttachmentRole(c *gin.Context, id uuid.UUID, role domain.ProjectRole) (*domain.Attachment, bool) {
+	attachment, err := h.attachmentService.GetAttachment(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	entityType, entityID := domain.EntityTypeTestResult, attachment.TestResultID
+	if attachment.TestCaseID != nil {
+		entityType, entityID = domain.EntityTypeTestCase, attachment.TestCaseID
+	}
+	if entityID == nil {
+		respondError(c, domain.NotFound("attachment"))
+		return nil, false
+	}
+
+	if !requireEntityRole(c, h.projectService, entityType, *entityID, role) {
+		return nil, false
+	}
+
+	return attachment, true
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChecklistHandler struct {
+	checklistService service.ChecklistService
+	projectService   service.ProjectService
+}
+
+func NewChecklistHandler(checklistService service.ChecklistService, projectService service.ProjectService) *ChecklistHandler {
+	return &ChecklistHandler{
+		checklistService: checklistService,
+		projectService:   projectService,
+	}
+}
+
+type ChecklistItemRequest struct {
+	Description    string `json:"description" binding:"required"`
+	ExpectedResult string `json:"expected_result"`
+	Order          int    `json:"order"`
+}
+
+type CreateChecklistRequest struct {
+	ProjectID   uuid.UUID              `json:"project_id" binding:"required"`
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Items       []ChecklistItemRequest `json:"items" binding:"dive"`
+}
+
+func (h *ChecklistHandler) CreateChecklist(c *gin.Context) {
+	var req CreateChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, req.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	checklist := &domain.Checklist{
+		ProjectID:   req.ProjectID,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID.(uuid.UUID),
+	}
+
+	// Convert items
+	for _, itemReq := range req.Items {
+		checklist.Items = append(checklist.Items, domain.ChecklistItem{
+			Description:    itemReq.Description,
+			ExpectedResult: itemReq.ExpectedResult,
+		})
+	}
+
+	if err := h.checklistService.CreateChecklist(c.Request.Context(), checklist); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, checklist.Version)
+	c.JSON(http.StatusCreated, checklist)
+}
+
+func (h *ChecklistHandler) GetChecklist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	checklist, err := h.checklistService.GetChecklist(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, checklist.ProjectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	setETag(c, checklist.Version)
+	c.JSON(http.StatusOK, checklist)
+}
+
+func (h *ChecklistHandler) ListChecklists(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Query("project_id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	checklists, total, err := h.checklistService.ListChecklists(c.Request.Context(), projectID, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  checklists,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+type UpdateChecklistRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Version is the version being updated; If-Match takes precedence
+	Version *int64 `json:"version"`
+}
+
+func (h *ChecklistHandler) UpdateChecklist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	var req UpdateChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	checklist, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	version, ok := requireVersion(c, req.Version, checklist.Version)
+	if !ok {
+		return
+	}
+	checklist.Version = version
+
+	if req.Name != "" {
+		checklist.Name = req.Name
+	}
+	if req.Description != "" {
+		checklist.Description = req.Description
+	}
+
+	if err := h.checklistService.UpdateChecklist(c.Request.Context(), checklist); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, checklist.Version)
+	c.JSON(http.StatusOK, checklist)
+}
+
+func (h *ChecklistHandler) DeleteChecklist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	if _, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.checklistService.DeleteChecklist(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist deleted successfully"})
+}
+
+func (h *ChecklistHandler) AddItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	item := &domain.ChecklistItem{
+		ChecklistID:    id,
+		Description:    req.Description,
+		ExpectedResult: req.ExpectedResult,
+		Order:          req.Order,
+	}
+
+	if err := h.checklistService.AddItem(c.Request.Context(), item); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *ChecklistHandler) UpdateItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		respondInvalidID(c, "checklist item")
+		return
+	}
+
+	var req ChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	item := &domain.ChecklistItem{
+		ID:             itemID,
+		ChecklistID:    id,
+		Description:    req.Description,
+		ExpectedResult: req.ExpectedResult,
+	}
+
+	if err := h.checklistService.UpdateItem(c.Request.Context(), item); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ChecklistHandler) RemoveItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		respondInvalidID(c, "checklist item")
+		return
+	}
+
+	if _, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.checklistService.RemoveItem(c.Request.Context(), id, itemID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item removed successfully"})
+}
+
+type ReorderChecklistItemsRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" binding:"required"`
+}
+
+func (h *ChecklistHandler) ReorderItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "checklist")
+		return
+	}
+
+	var req ReorderChecklistItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireChecklistRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.checklistService.ReorderItems(c.Request.Context(), id, req.ItemIDs); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	checklist, err := h.checklistService.GetChecklist(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, checklist.Version)
+	c.JSON(http.StatusOK, checklist)
+}
+
+// requireChecklistRole loads the checklist and checks the caller's role in its project.
+func (h *ChecklistHandler) requireChecklistRole(c *gin.Context, id uuid.UUID, role domain.ProjectRole) (*domain.Checklist, bool) {
+	checklist, err := h.checklistService.GetChecklist(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !requireProjectRole(c, h.projectService, checklist.ProjectID, role) {
+		return nil, false
+	}
+
+	return checklist, true
+}
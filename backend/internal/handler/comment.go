@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+	projectService service.ProjectService
+}
+
+func NewCommentHandler(commentService service.CommentService, projectService service.ProjectService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		projectService: projectService,
+	}
+}
+
+type CreateCommentRequest struct {
+	EntityType string     `json:"entity_type" binding:"required"`
+	EntityID   uuid.UUID  `json:"entity_id" binding:"required"`
+	ParentID   *uuid.UUID `json:"parent_id"`
+	Content    string     `json:"content" binding:"required"`
+}
+
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !domain.ValidEntityType(req.EntityType) {
+		respondError(c, errUnsupportedEntityType)
+		return
+	}
+
+	// Any project member may take part in a discussion
+	if !requireEntityRole(c, h.projectService, req.EntityType, req.EntityID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	comment := &domain.Comment{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		ParentID:   req.ParentID,
+		Content:    req.Content,
+		CreatedBy:  userID.(uuid.UUID),
+	}
+
+	if err := h.commentService.CreateComment(c.Request.Context(), comment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	entityType := c.Query("entity_type")
+	if !domain.ValidEntityType(entityType) {
+		respondError(c, errUnsupportedEntityType)
+		return
+	}
+
+	entityID, err := uuid.Parse(c.Query("entity_id"))
+	if err != nil {
+		respondInvalidID(c, "entity")
+		return
+	}
+
+	if !requireEntityRole(c, h.projectService, entityType, entityID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	var resolved *bool
+	if value := c.Query("resolved"); value != "" {
+		parsed := value == "true"
+		resolved = &parsed
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	comments, total, err := h.commentService.ListComments(c.Request.Context(), entityType, entityID, resolved, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  comments,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+func (h *CommentHandler) GetComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "comment")
+		return
+	}
+
+	comment, ok := h.requireCommentRole(c, id, domain.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "comment")
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	comment, ok := h.requireCommentRole(c, id, domain.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	// Only the author can change what they wrote
+	userID, _ := c.Get("userID")
+	if comment.CreatedBy != userID.(uuid.UUID) {
+		respondError(c, domain.Forbidden("not_comment_author", "only the author can edit a comment"))
+		return
+	}
+
+	comment.Content = req.Content
+
+	if err := h.commentService.UpdateComment(c.Request.Context(), comment); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "comment")
+		return
+	}
+
+	comment, ok := h.requireCommentRole(c, id, domain.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	// Authors can delete their own comments, project owners can moderate
+	userID, _ := c.Get("userID")
+	if comment.CreatedBy != userID.(uuid.UUID) &&
+		!requireEntityRole(c, h.projectService, comment.EntityType, comment.EntityID, domain.ProjectRoleOwner) {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func (h *CommentHandler) ResolveComment(c *gin.Context) {
+	h.setResolved(c, true)
+}
+
+func (h *CommentHandler) ReopenComment(c *gin.Context) {
+	h.setResolved(c, false)
+}
+
+func (h *CommentHandler) setResolved(c *gin.Context, resolved bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "comment")
+		return
+	}
+
+	if _, ok := h.requireCommentRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	comment, err := h.commentService.SetResolved(c.Request.Context(), id, userID.(uuid.UUID), resolved)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// requireCommentRole loads the comment and checks the caller's role in the
+// project of the entity it was posted on.
+func (h *CommentHandler) requireCommentRole(c *gin.Context, id uuid.UUID, role domain.ProjectRole) (*domain.Comment, bool) {
+	comment, err := h.commentService.GetComment(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !requireEntityRole(c, h.projectService, comment.EntityType, comment.EntityID, role) {
+		return nil, false
+	}
+
+	return comment, true
+}
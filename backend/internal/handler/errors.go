@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// errorResponse is the body of every error response. Error is kept for
+// clients that only show a message; Code is stable and meant for programs.
+// Current carries the stored copy of the entity on a version conflict.
+type errorResponse struct {
+	Error   string              `json:"error"`
+	Code    string              `json:"code"`
+	Fields  []domain.FieldError `json:"fields,omitempty"`
+	Current interface{}         `json:"current,omitempty"`
+}
+
+var (
+	errUnauthorized          = domain.Unauthorized("unauthorized", "unauthorized")
+	errFileRequired          = domain.Validation("file_required", "file is required", domain.FieldError{Field: "file", Message: "is required"})
+	errInvalidDeadline       = domain.Validation("invalid_deadline", "invalid deadline format", domain.FieldError{Field: "deadline", Message: "must be an RFC 3339 timestamp"})
+	errUnsupportedEntityType = domain.Validation("unsupported_entity_type", "unsupported entity type", domain.FieldError{Field: "entity_type", Message: "must be a project-scoped entity type"})
+)
+
+func init() {
+	// Report validation errors under the JSON or form name of the field
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// respondError writes err with the status matching its domain error kind.
+// Errors that are not domain errors are logged and reported as 500 without
+// their details.
+func respondError(c *gin.Context, err error) {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		respondStatus(c, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(domainErr, domain.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(domainErr, domain.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(domainErr, domain.ErrValidation):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(domainErr, domain.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(domainErr, domain.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	}
+
+	c.JSON(status, errorResponse{Error: domainErr.Message, Code: domainErr.Code, Fields: domainErr.Fields, Current: domainErr.Current})
+}
+
+// respondStatus writes an error body for failures that have no domain
+// error, such as malformed requests.
+func respondStatus(c *gin.Context, status int, code, message string) {
+	c.JSON(status, errorResponse{Error: message, Code: code})
+}
+
+// respondInvalidID reports a path or query parameter that is not a UUID.
+func respondInvalidID(c *gin.Context, entity string) {
+	respondStatus(c, http.StatusBadRequest, "invalid_id", "invalid "+entity+" ID")
+}
+
+// respondBindError reports a request that could not be bound: 422 with the
+// offending fields when validation failed, 400 when the body is malformed.
+func respondBindError(c *gin.Context, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		respondStatus(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, domain.FieldError{Field: fieldPath(fe), Message: validationMessage(fe)})
+	}
+	respondError(c, domain.Validation("validation_failed", "request validation failed", fields...))
+}
+
+// fieldPath drops the request struct name from the namespace of a field,
+// turning "CreateTestCaseRequest.steps[0].action" into "steps[0].action".
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func validationMessage(fe validator.FieldError) string {
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	} else if fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map {
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", fe.Param(), unit)
+	case "gt", "gte", "lt", "lte":
+		return fmt.Sprintf("must be %s %s", map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[fe.Tag()], fe.Param())
+	}
+	return "is invalid"
+}
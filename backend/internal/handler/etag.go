@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sends the version of an entity as its entity tag.
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// requireVersion returns the version an update is based on: the If-Match
+// header when present, else the version field of the body. If-Match: * and
+// a list naming the current version both accept current. Without either it
+// responds with 428, since a blind update could overwrite someone's changes.
+func requireVersion(c *gin.Context, bodyVersion *int64, current int64) (int64, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if bodyVersion == nil {
+			respondStatus(c, http.StatusPreconditionRequired, "version_required",
+				"send the version being updated in the If-Match header or the version field")
+			return 0, false
+		}
+		return *bodyVersion, true
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return current, true
+		}
+		// Versions are compared exactly, so weak tags are as good as strong ones
+		unquoted, err := strconv.Unquote(strings.TrimPrefix(tag, "W/"))
+		if err != nil {
+			respondStatus(c, http.StatusBadRequest, "invalid_if_match", "If-Match must list quoted entity tags")
+			return 0, false
+		}
+		version, err := strconv.ParseInt(unquoted, 10, 64)
+		if err != nil {
+			respondStatus(c, http.StatusBadRequest, "invalid_if_match", "If-Match must name a version returned as ETag")
+			return 0, false
+		}
+		if version == current {
+			return current, true
+		}
+		versions = append(versions, version)
+	}
+	return versions[0], true
+}
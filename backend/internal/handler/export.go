@@ -3,18 +3,24 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/AntVerkh/test-management-system/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type ExportHandler struct {
-	exportService service.ExportService
+	exportService  service.ExportService
+	projectService service.ProjectService
 }
 
-func NewExportHandler(exportService service.ExportService) *ExportHandler {
-	return &ExportHandler{exportService: exportService}
+func NewExportHandler(exportService service.ExportService, projectService service.ProjectService) *ExportHandler {
+	return &ExportHandler{
+		exportService:  exportService,
+		projectService: projectService,
+	}
 }
 
 type ExportRequest struct {
@@ -28,7 +34,11 @@ type ExportRequest struct {
 func (h *ExportHandler) Export(c *gin.Context) {
 	var req ExportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	if !h.requireExportAccess(c, req.EntityType, req.EntityID) {
 		return
 	}
 
@@ -41,12 +51,12 @@ func (h *ExportHandler) Export(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	// Set headers for file download
-	c.Header("Content-Type", "text/markdown; charset=utf-8")
+	c.Header("Content-Type", h.contentType(req.Format))
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Header("Content-Length", strconv.Itoa(len(content)))
 
@@ -80,6 +90,10 @@ func (h *ExportHandler) exportEntity(c *gin.Context, entityType string) {
 	includeHistory := c.DefaultQuery("include_history", "false") == "true"
 	includeComments := c.DefaultQuery("include_comments", "false") == "true"
 
+	if !h.requireExportAccess(c, entityType, entityID) {
+		return
+	}
+
 	content, filename, err := h.exportService.ExportEntity(c.Request.Context(), &domain.ExportRequest{
 		EntityType:      entityType,
 		EntityID:        entityID,
@@ -89,14 +103,68 @@ func (h *ExportHandler) exportEntity(c *gin.Context, entityType string) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	// Set headers for file download
-	c.Header("Content-Type", "text/markdown; charset=utf-8")
+	c.Header("Content-Type", h.contentType(format))
 	c.Header("Content-Disposition", "attachment; filename="+filename)
 	c.Header("Content-Length", strconv.Itoa(len(content)))
 
 	c.String(http.StatusOK, content)
 }
+
+// ExportProjectTestCases exports every test case of a project as one
+// spreadsheet.
+func (h *ExportHandler) ExportProjectTestCases(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	format := domain.ExportFormat(c.DefaultQuery("format", "csv"))
+
+	content, filename, err := h.exportService.ExportProjectTestCases(c.Request.Context(), projectID, format)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Set headers for file download
+	c.Header("Content-Type", h.contentType(format))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("Content-Length", strconv.Itoa(len(content)))
+
+	c.String(http.StatusOK, content)
+}
+
+// requireExportAccess checks that the caller can view the project of the
+// exported entity, whose history and comments may be included as well.
+func (h *ExportHandler) requireExportAccess(c *gin.Context, entityType, rawID string) bool {
+	entityID, err := uuid.Parse(rawID)
+	if err != nil {
+		respondInvalidID(c, strings.ReplaceAll(entityType, "_", " "))
+		return false
+	}
+
+	return requireEntityRole(c, h.projectService, entityType, entityID, domain.ProjectRoleViewer)
+}
+
+// ListFormats returns the registered export formats for building export menus.
+func (h *ExportHandler) ListFormats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.exportService.ListFormats())
+}
+
+func (h *ExportHandler) contentType(format domain.ExportFormat) string {
+	info, err := h.exportService.GetFormat(format)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return info.MimeType
+}
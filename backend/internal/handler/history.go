@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HistoryHandler struct {
+	historyService service.HistoryService
+	projectService service.ProjectService
+}
+
+func NewHistoryHandler(historyService service.HistoryService, projectService service.ProjectService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+		projectService: projectService,
+	}
+}
+
+func (h *HistoryHandler) ListTestPlanHistory(c *gin.Context) {
+	h.listHistory(c, domain.EntityTypeTestPlan)
+}
+
+func (h *HistoryHandler) ListTestCaseHistory(c *gin.Context) {
+	h.listHistory(c, domain.EntityTypeTestCase)
+}
+
+func (h *HistoryHandler) ListChecklistHistory(c *gin.Context) {
+	h.listHistory(c, domain.EntityTypeChecklist)
+}
+
+func (h *HistoryHandler) ListTestStrategyHistory(c *gin.Context) {
+	h.listHistory(c, domain.EntityTypeTestStrategy)
+}
+
+func (h *HistoryHandler) ListTestRunHistory(c *gin.Context) {
+	h.listHistory(c, domain.EntityTypeTestRun)
+}
+
+func (h *HistoryHandler) listHistory(c *gin.Context, entityType string) {
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "entity")
+		return
+	}
+
+	if !requireEntityRole(c, h.projectService, entityType, entityID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	entries, total, err := h.historyService.ListHistory(c.Request.Context(), entityType, entityID, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  entries,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MFAHandler struct {
+	mfaService  service.MFAService
+	userService service.UserService
+}
+
+func NewMFAHandler(mfaService service.MFAService, userService service.UserService) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+	}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	h.respondStatus(c, userID.(uuid.UUID))
+}
+
+// Enroll returns a new secret to add to an authenticator app; it is not in
+// effect until confirmed with a code.
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	setup, err := h.mfaService.Enroll(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// Confirm enables two-factor authentication and returns the recovery codes,
+// which are not shown again.
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	codes, err := h.mfaService.Confirm(c.Request.Context(), userID.(uuid.UUID), req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes; it needs an authenticator code.
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID.(uuid.UUID), req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID.(uuid.UUID), req.Code); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *MFAHandler) GetUserStatus(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "user")
+		return
+	}
+
+	h.respondStatus(c, userID)
+}
+
+// ResetUser removes a user's enrollment, e.g. after they lost their device.
+func (h *MFAHandler) ResetUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "user")
+		return
+	}
+
+	if err := h.mfaService.Reset(c.Request.Context(), userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset successfully"})
+}
+
+func (h *MFAHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.mfaService.ListPolicies(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+type SetMFAPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+func (h *MFAHandler) SetPolicy(c *gin.Context) {
+	var req SetMFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+	updatedBy := userID.(uuid.UUID)
+
+	policy := &domain.MFAPolicy{
+		Role:      domain.UserRole(c.Param("role")),
+		Required:  *req.Required,
+		UpdatedBy: &updatedBy,
+	}
+
+	if err := h.mfaService.SetPolicy(c.Request.Context(), policy); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *MFAHandler) respondStatus(c *gin.Context, userID uuid.UUID) {
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	status, err := h.mfaService.Status(c.Request.Context(), user)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
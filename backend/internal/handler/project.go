@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ProjectHandler struct {
+	projectService service.ProjectService
+}
+
+func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{projectService: projectService}
+}
+
+// requireProjectRole checks that the authenticated user holds at least the
+// given role in the project and aborts the request with 403 otherwise.
+func requireProjectRole(c *gin.Context, projectService service.ProjectService, projectID uuid.UUID, role domain.ProjectRole) bool {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return false
+	}
+	userRole, _ := c.Get("userRole")
+	globalRole, _ := userRole.(domain.UserRole)
+
+	if err := projectService.CheckAccess(c.Request.Context(), projectID, userID.(uuid.UUID), globalRole, role); err != nil {
+		respondError(c, err)
+		return false
+	}
+
+	return true
+}
+
+// requireEntityRole resolves the project of any project-scoped entity and
+// checks the caller's role in it.
+func requireEntityRole(c *gin.Context, projectService service.ProjectService, entityType string, entityID uuid.UUID, role domain.ProjectRole) bool {
+	projectID, err := projectService.GetEntityProjectID(c.Request.Context(), entityType, entityID)
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+
+	return requireProjectRole(c, projectService, projectID, role)
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	project := &domain.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userID.(uuid.UUID),
+	}
+
+	if err := h.projectService.CreateProject(c.Request.Context(), project); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleViewer) {
+		return
+	}
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+	userRole, _ := c.Get("userRole")
+	globalRole, _ := userRole.(domain.UserRole)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	projects, total, err := h.projectService.ListProjects(c.Request.Context(), userID.(uuid.UUID), globalRole, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  projects,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleOwner) {
+		return
+	}
+
+	project, err := h.projectService.GetProject(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if req.Name != "" {
+		project.Name = req.Name
+	}
+	if req.Description != "" {
+		project.Description = req.Description
+	}
+
+	if err := h.projectService.UpdateProject(c.Request.Context(), project); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) ListMembers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleViewer) {
+		return
+	}
+
+	members, err := h.projectService.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID          `json:"user_id" binding:"required"`
+	Role   domain.ProjectRole `json:"role" binding:"required"`
+}
+
+func (h *ProjectHandler) AddMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	var req AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleOwner) {
+		return
+	}
+
+	member, err := h.projectService.SetMember(c.Request.Context(), id, req.UserID, req.Role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+type UpdateProjectMemberRequest struct {
+	Role domain.ProjectRole `json:"role" binding:"required"`
+}
+
+func (h *ProjectHandler) UpdateMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondInvalidID(c, "user")
+		return
+	}
+
+	var req UpdateProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleOwner) {
+		return
+	}
+
+	member, err := h.projectService.SetMember(c.Request.Context(), id, userID, req.Role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		respondInvalidID(c, "user")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, id, domain.ProjectRoleOwner) {
+		return
+	}
+
+	if err := h.projectService.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project member removed successfully"})
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ServiceAccountHandler manages a project's service accounts and their
+// tokens, which is reserved for project owners.
+type ServiceAccountHandler struct {
+	accountService service.ServiceAccountService
+	tokenService   service.APITokenService
+	projectService service.ProjectService
+}
+
+func NewServiceAccountHandler(accountService service.ServiceAccountService, tokenService service.APITokenService, projectService service.ProjectService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		accountService: accountService,
+		tokenService:   tokenService,
+		projectService: projectService,
+	}
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string             `json:"name" binding:"required"`
+	Description string             `json:"description"`
+	Role        domain.ProjectRole `json:"role"` // defaults to editor
+}
+
+func (h *ServiceAccountHandler) ListServiceAccounts(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleOwner) {
+		return
+	}
+
+	accounts, err := h.accountService.ListServiceAccounts(c.Request.Context(), projectID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleOwner) {
+		return
+	}
+
+	account := &domain.ServiceAccount{
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        req.Role,
+		CreatedBy:   userID.(uuid.UUID),
+	}
+
+	if err := h.accountService.CreateServiceAccount(c.Request.Context(), account); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// DeleteServiceAccount removes the account from its project and revokes its tokens.
+func (h *ServiceAccountHandler) DeleteServiceAccount(c *gin.Context) {
+	account, ok := h.requireServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.DeleteServiceAccount(c.Request.Context(), account.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted successfully"})
+}
+
+func (h *ServiceAccountHandler) ListTokens(c *gin.Context) {
+	account, ok := h.requireServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(c.Request.Context(), account.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *ServiceAccountHandler) CreateToken(c *gin.Context) {
+	account, ok := h.requireServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	createAPIToken(c, h.tokenService, account.UserID)
+}
+
+func (h *ServiceAccountHandler) RevokeToken(c *gin.Context) {
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		respondInvalidID(c, "token")
+		return
+	}
+
+	account, ok := h.requireServiceAccount(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.tokenService.GetToken(c.Request.Context(), tokenID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if token.UserID != account.UserID {
+		respondError(c, domain.NotFound("token"))
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(c.Request.Context(), tokenID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
+
+// requireServiceAccount loads the service account named in the path, checks it
+// belongs to the project in the path and that the caller owns that project.
+func (h *ServiceAccountHandler) requireServiceAccount(c *gin.Context) (*domain.ServiceAccount, bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return nil, false
+	}
+
+	accountID, err := uuid.Parse(c.Param("accountId"))
+	if err != nil {
+		respondInvalidID(c, "service account")
+		return nil, false
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleOwner) {
+		return nil, false
+	}
+
+	account, err := h.accountService.GetServiceAccount(c.Request.Context(), accountID)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	if account.ProjectID != projectID {
+		respondError(c, domain.NotFound("service account"))
+		return nil, false
+	}
+
+	return account, true
+}
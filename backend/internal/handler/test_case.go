@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/AntVerkh/test-management-system/internal/service"
@@ -12,10 +16,16 @@ import (
 
 type TestCaseHandler struct {
 	testCaseService service.TestCaseService
+	projectService  service.ProjectService
+	maxUploadSize   int64
 }
 
-func NewTestCaseHandler(testCaseService service.TestCaseService) *TestCaseHandler {
-	return &TestCaseHandler{testCaseService: testCaseService}
+func NewTestCaseHandler(testCaseService service.TestCaseService, projectService service.ProjectService, maxUploadSize int64) *TestCaseHandler {
+	return &TestCaseHandler{
+		testCaseService: testCaseService,
+		projectService:  projectService,
+		maxUploadSize:   maxUploadSize,
+	}
 }
 
 type CreateTestCaseRequest struct {
@@ -25,24 +35,33 @@ type CreateTestCaseRequest struct {
 	PreSteps       string            `json:"pre_steps"`
 	Steps          []TestStepRequest `json:"steps"`
 	ExpectedResult string            `json:"expected_result"`
+
+	AutomationStatus domain.AutomationStatus `json:"automation_status" binding:"omitempty,oneof=manual automated to_be_automated"`
+	AutomationIDs    []string                `json:"automation_ids" binding:"dive,max=512"`
 }
 
 type TestStepRequest struct {
-	Description    string `json:"description" binding:"required"`
-	ExpectedResult string `json:"expected_result"`
-	Order          int    `json:"order"`
+	// ID names an existing step to keep when replacing the steps of a test case
+	ID             uuid.UUID `json:"id"`
+	Description    string    `json:"description" binding:"required"`
+	ExpectedResult string    `json:"expected_result"`
+	Order          int       `json:"order"`
 }
 
 func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 	var req CreateTestCaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, req.ProjectID, domain.ProjectRoleEditor) {
 		return
 	}
 
@@ -53,7 +72,10 @@ func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 		PreSteps:       req.PreSteps,
 		ExpectedResult: req.ExpectedResult,
 		CreatedBy:      userID.(uuid.UUID),
+
+		AutomationStatus: req.AutomationStatus,
 	}
+	testCase.SetAutomationKeys(req.AutomationIDs)
 
 	// Convert steps
 	for _, stepReq := range req.Steps {
@@ -65,42 +87,62 @@ func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 	}
 
 	if err := h.testCaseService.CreateTestCase(c.Request.Context(), testCase); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, testCase.Version)
 	c.JSON(http.StatusCreated, testCase)
 }
 
 func (h *TestCaseHandler) GetTestCase(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		respondInvalidID(c, "test case")
 		return
 	}
 
 	testCase, err := h.testCaseService.GetTestCase(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+		respondError(c, err)
 		return
 	}
 
+	if !requireProjectRole(c, h.projectService, testCase.ProjectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	setETag(c, testCase.Version)
 	c.JSON(http.StatusOK, testCase)
 }
 
 func (h *TestCaseHandler) ListTestCases(c *gin.Context) {
 	projectID, err := uuid.Parse(c.Query("project_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	filter := domain.TestCaseFilter{
+		AutomationStatus: domain.AutomationStatus(c.Query("automation_status")),
+		AutomationID:     c.Query("automation_id"),
+	}
+	if filter.AutomationStatus != "" && !domain.ValidAutomationStatus(filter.AutomationStatus) {
+		respondError(c, domain.Validation("invalid_automation_status", "invalid automation status",
+			domain.FieldError{Field: "automation_status", Message: "must be one of manual, automated or to_be_automated"}))
 		return
 	}
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
 
-	testCases, total, err := h.testCaseService.ListTestCases(c.Request.Context(), projectID, page, size)
+	testCases, total, err := h.testCaseService.ListTestCases(c.Request.Context(), projectID, filter, page, size)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -113,32 +155,48 @@ func (h *TestCaseHandler) ListTestCases(c *gin.Context) {
 }
 
 type UpdateTestCaseRequest struct {
-	Title          string            `json:"title"`
-	Description    string            `json:"description"`
-	PreSteps       string            `json:"pre_steps"`
-	Steps          []TestStepRequest `json:"steps"`
-	ExpectedResult string            `json:"expected_result"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description"`
+	PreSteps       string             `json:"pre_steps"`
+	Steps          *[]TestStepRequest `json:"steps" binding:"omitempty,dive"` // replaces all steps when present
+	ExpectedResult string             `json:"expected_result"`
+
+	AutomationStatus domain.AutomationStatus `json:"automation_status" binding:"omitempty,oneof=manual automated to_be_automated"`
+	// AutomationIDs replaces the identifiers when present; an empty list clears them
+	AutomationIDs *[]string `json:"automation_ids" binding:"omitempty,dive,max=512"`
+	// Version is the version being updated; If-Match takes precedence
+	Version *int64 `json:"version"`
 }
 
 func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		respondInvalidID(c, "test case")
 		return
 	}
 
 	var req UpdateTestCaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	// Get existing test case
 	testCase, err := h.testCaseService.GetTestCase(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, testCase.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	version, ok := requireVersion(c, req.Version, testCase.Version)
+	if !ok {
 		return
 	}
+	testCase.Version = version
 
 	// Update fields
 	if req.Title != "" {
@@ -153,12 +211,19 @@ func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 	if req.ExpectedResult != "" {
 		testCase.ExpectedResult = req.ExpectedResult
 	}
+	if req.AutomationStatus != "" {
+		testCase.AutomationStatus = req.AutomationStatus
+	}
+	if req.AutomationIDs != nil {
+		testCase.SetAutomationKeys(*req.AutomationIDs)
+	}
 
-	// Update steps if provided
-	if len(req.Steps) > 0 {
-		testCase.Steps = nil
-		for _, stepReq := range req.Steps {
+	// Replace steps if provided
+	if req.Steps != nil {
+		testCase.Steps = make([]domain.TestStep, 0, len(*req.Steps))
+		for _, stepReq := range *req.Steps {
 			testCase.Steps = append(testCase.Steps, domain.TestStep{
+				ID:             stepReq.ID,
 				Description:    stepReq.Description,
 				ExpectedResult: stepReq.ExpectedResult,
 				Order:          stepReq.Order,
@@ -167,9 +232,310 @@ func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 	}
 
 	if err := h.testCaseService.UpdateTestCase(c.Request.Context(), testCase); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, testCase.Version)
 	c.JSON(http.StatusOK, testCase)
 }
+
+// DeleteTestCase moves the test case to the trash. Results of past runs keep
+// showing it.
+func (h *TestCaseHandler) DeleteTestCase(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	testCase, err := h.testCaseService.GetTestCase(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, testCase.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	if err := h.testCaseService.DeleteTestCase(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test case deleted successfully"})
+}
+
+// AddStep inserts a step at the requested order, or appends it.
+func (h *TestCaseHandler) AddStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	var req TestStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireTestCaseRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	step := &domain.TestStep{
+		TestCaseID:     id,
+		Description:    req.Description,
+		ExpectedResult: req.ExpectedResult,
+		Order:          req.Order,
+	}
+
+	if err := h.testCaseService.AddStep(c.Request.Context(), step); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, step)
+}
+
+func (h *TestCaseHandler) UpdateStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	stepID, err := uuid.Parse(c.Param("stepId"))
+	if err != nil {
+		respondInvalidID(c, "test step")
+		return
+	}
+
+	var req TestStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireTestCaseRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	step := &domain.TestStep{
+		ID:             stepID,
+		TestCaseID:     id,
+		Description:    req.Description,
+		ExpectedResult: req.ExpectedResult,
+	}
+
+	if err := h.testCaseService.UpdateStep(c.Request.Context(), step); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, step)
+}
+
+func (h *TestCaseHandler) RemoveStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	stepID, err := uuid.Parse(c.Param("stepId"))
+	if err != nil {
+		respondInvalidID(c, "test step")
+		return
+	}
+
+	if _, ok := h.requireTestCaseRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.testCaseService.RemoveStep(c.Request.Context(), id, stepID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test step removed successfully"})
+}
+
+type MoveTestStepRequest struct {
+	Order int `json:"order" binding:"required,min=1"`
+}
+
+// MoveStep moves a step to a new 1-based position and returns the test case
+// with its renumbered steps.
+func (h *TestCaseHandler) MoveStep(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test case")
+		return
+	}
+
+	stepID, err := uuid.Parse(c.Param("stepId"))
+	if err != nil {
+		respondInvalidID(c, "test step")
+		return
+	}
+
+	var req MoveTestStepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if _, ok := h.requireTestCaseRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.testCaseService.MoveStep(c.Request.Context(), id, stepID, req.Order); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	testCase, err := h.testCaseService.GetTestCase(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, testCase.Version)
+	c.JSON(http.StatusOK, testCase)
+}
+
+// requireTestCaseRole loads the test case and checks the caller's role in its project.
+func (h *TestCaseHandler) requireTestCaseRole(c *gin.Context, id uuid.UUID, role domain.ProjectRole) (*domain.TestCase, bool) {
+	testCase, err := h.testCaseService.GetTestCase(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !requireProjectRole(c, h.projectService, testCase.ProjectID, role) {
+		return nil, false
+	}
+
+	return testCase, true
+}
+
+// GetAutomationCoverage reports how many test cases of the project are
+// automated, manual or waiting to be automated.
+func (h *TestCaseHandler) GetAutomationCoverage(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	coverage, err := h.testCaseService.GetAutomationCoverage(c.Request.Context(), projectID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coverage)
+}
+
+// ImportTestCases creates test cases from an uploaded CSV, XLSX, TestRail CSV
+// or JUnit XML file. The multipart form takes the file, an optional format
+// (guessed from the file extension otherwise), an optional JSON column
+// mapping and dry_run=true to validate without creating anything.
+func (h *TestCaseHandler) ImportTestCases(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	// Leave room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, errFileRequired)
+		return
+	}
+	if fileHeader.Size > h.maxUploadSize {
+		respondStatus(c, http.StatusRequestEntityTooLarge, "file_too_large", "file is too large")
+		return
+	}
+
+	format := domain.ImportFormat(c.PostForm("format"))
+	if format == "" {
+		format = importFormatFromFilename(fileHeader.Filename)
+	}
+	switch format {
+	case domain.ImportFormatCSV, domain.ImportFormatXLSX, domain.ImportFormatTestRail, domain.ImportFormatJUnit:
+	default:
+		respondError(c, domain.Validation("unsupported_import_format", "format must be one of csv, xlsx, testrail or junit",
+			domain.FieldError{Field: "format", Message: "must be one of csv, xlsx, testrail or junit"}))
+		return
+	}
+
+	var mapping domain.ImportMapping
+	if raw := c.PostForm("mapping"); raw != "" {
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&mapping); err != nil {
+			respondError(c, domain.Validation("invalid_mapping", "invalid column mapping: "+err.Error(),
+				domain.FieldError{Field: "mapping", Message: err.Error()}))
+			return
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondStatus(c, http.StatusBadRequest, "invalid_file", err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondStatus(c, http.StatusBadRequest, "invalid_file", err.Error())
+		return
+	}
+
+	report, err := h.testCaseService.ImportTestCases(c.Request.Context(), projectID, userID.(uuid.UUID), format, data, mapping, dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun || report.TestCasesCreated == 0 {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
+
+func importFormatFromFilename(filename string) domain.ImportFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		return domain.ImportFormatXLSX
+	case ".xml":
+		return domain.ImportFormatJUnit
+	default:
+		return domain.ImportFormatCSV
+	}
+}
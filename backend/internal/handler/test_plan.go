@@ -13,10 +13,14 @@ import (
 
 type TestPlanHandler struct {
 	testPlanService service.TestPlanService
+	projectService  service.ProjectService
 }
 
-func NewTestPlanHandler(testPlanService service.TestPlanService) *TestPlanHandler {
-	return &TestPlanHandler{testPlanService: testPlanService}
+func NewTestPlanHandler(testPlanService service.TestPlanService, projectService service.ProjectService) *TestPlanHandler {
+	return &TestPlanHandler{
+		testPlanService: testPlanService,
+		projectService:  projectService,
+	}
 }
 
 type CreateTestPlanRequest struct {
@@ -29,13 +33,17 @@ type CreateTestPlanRequest struct {
 func (h *TestPlanHandler) CreateTestPlan(c *gin.Context) {
 	var req CreateTestPlanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, req.ProjectID, domain.ProjectRoleEditor) {
 		return
 	}
 
@@ -44,7 +52,7 @@ func (h *TestPlanHandler) CreateTestPlan(c *gin.Context) {
 		var err error
 		deadline, err = time.Parse(time.RFC3339, req.Deadline)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid deadline format"})
+			respondError(c, errInvalidDeadline)
 			return
 		}
 	}
@@ -58,33 +66,43 @@ func (h *TestPlanHandler) CreateTestPlan(c *gin.Context) {
 	}
 
 	if err := h.testPlanService.CreateTestPlan(c.Request.Context(), plan); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, plan.Version)
 	c.JSON(http.StatusCreated, plan)
 }
 
 func (h *TestPlanHandler) GetTestPlan(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test plan ID"})
+		respondInvalidID(c, "test plan")
 		return
 	}
 
 	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "test plan not found"})
+		respondError(c, err)
 		return
 	}
 
+	if !requireProjectRole(c, h.projectService, plan.ProjectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	setETag(c, plan.Version)
 	c.JSON(http.StatusOK, plan)
 }
 
 func (h *TestPlanHandler) ListTestPlans(c *gin.Context) {
 	projectID, err := uuid.Parse(c.Query("project_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
 		return
 	}
 
@@ -93,7 +111,7 @@ func (h *TestPlanHandler) ListTestPlans(c *gin.Context) {
 
 	plans, total, err := h.testPlanService.ListTestPlans(c.Request.Context(), projectID, page, size)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -110,26 +128,38 @@ type UpdateTestPlanRequest struct {
 	Description string `json:"description"`
 	Deadline    string `json:"deadline"`
 	Status      string `json:"status"`
+	// Version is the version being updated; If-Match takes precedence
+	Version *int64 `json:"version"`
 }
 
 func (h *TestPlanHandler) UpdateTestPlan(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test plan ID"})
+		respondInvalidID(c, "test plan")
 		return
 	}
 
 	var req UpdateTestPlanRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
 		return
 	}
 
 	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "test plan not found"})
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, plan.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	version, ok := requireVersion(c, req.Version, plan.Version)
+	if !ok {
 		return
 	}
+	plan.Version = version
 
 	if req.Name != "" {
 		plan.Name = req.Name
@@ -140,7 +170,7 @@ func (h *TestPlanHandler) UpdateTestPlan(c *gin.Context) {
 	if req.Deadline != "" {
 		deadline, err := time.Parse(time.RFC3339, req.Deadline)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid deadline format"})
+			respondError(c, errInvalidDeadline)
 			return
 		}
 		plan.Deadline = deadline
@@ -150,10 +180,11 @@ func (h *TestPlanHandler) UpdateTestPlan(c *gin.Context) {
 	}
 
 	if err := h.testPlanService.UpdateTestPlan(c.Request.Context(), plan); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	setETag(c, plan.Version)
 	c.JSON(http.StatusOK, plan)
 }
 
@@ -164,20 +195,91 @@ type AddTestCaseRequest struct {
 func (h *TestPlanHandler) AddTestCase(c *gin.Context) {
 	planID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test plan ID"})
+		respondInvalidID(c, "test plan")
 		return
 	}
 
 	var req AddTestCaseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBindError(c, err)
+		return
+	}
+
+	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), planID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, plan.ProjectID, domain.ProjectRoleEditor) {
 		return
 	}
 
 	if err := h.testPlanService.AddTestCaseToPlan(c.Request.Context(), planID, req.TestCaseID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Test case added to plan successfully"})
 }
+
+type AddChecklistRequest struct {
+	ChecklistID uuid.UUID `json:"checklist_id" binding:"required"`
+}
+
+func (h *TestPlanHandler) AddChecklist(c *gin.Context) {
+	planID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test plan")
+		return
+	}
+
+	var req AddChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), planID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, plan.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	if err := h.testPlanService.AddChecklistToPlan(c.Request.Context(), planID, req.ChecklistID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist added to plan successfully"})
+}
+
+// DeleteTestPlan moves the plan to the trash together with its test runs.
+func (h *TestPlanHandler) DeleteTestPlan(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test plan")
+		return
+	}
+
+	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, plan.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	if err := h.testPlanService.DeleteTestPlan(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test plan deleted successfully"})
+}
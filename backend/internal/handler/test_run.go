@@ -0,0 +1,327 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TestRunHandler struct {
+	testRunService  service.TestRunService
+	testPlanService service.TestPlanService
+	projectService  service.ProjectService
+	maxUploadSize   int64
+}
+
+func NewTestRunHandler(testRunService service.TestRunService, testPlanService service.TestPlanService, projectService service.ProjectService, maxUploadSize int64) *TestRunHandler {
+	return &TestRunHandler{
+		testRunService:  testRunService,
+		testPlanService: testPlanService,
+		projectService:  projectService,
+		maxUploadSize:   maxUploadSize,
+	}
+}
+
+// requirePlanRole resolves the project of a test plan and checks the caller's
+// role in it, since test runs are only linked to projects through their plan.
+func (h *TestRunHandler) requirePlanRole(c *gin.Context, testPlanID uuid.UUID, role domain.ProjectRole) bool {
+	plan, err := h.testPlanService.GetTestPlan(c.Request.Context(), testPlanID)
+	if err != nil {
+		respondError(c, err)
+		return false
+	}
+
+	return requireProjectRole(c, h.projectService, plan.ProjectID, role)
+}
+
+// requireRunRole loads the test run and checks the caller's role in its project.
+func (h *TestRunHandler) requireRunRole(c *gin.Context, testRunID uuid.UUID, role domain.ProjectRole) (*domain.TestRun, bool) {
+	testRun, err := h.testRunService.GetTestRun(c.Request.Context(), testRunID)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+
+	if !h.requirePlanRole(c, testRun.TestPlanID, role) {
+		return nil, false
+	}
+
+	return testRun, true
+}
+
+type StartTestRunRequest struct {
+	TestPlanID uuid.UUID `json:"test_plan_id" binding:"required"`
+	Name       string    `json:"name" binding:"required"`
+}
+
+func (h *TestRunHandler) StartTestRun(c *gin.Context) {
+	var req StartTestRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !h.requirePlanRole(c, req.TestPlanID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	testRun := &domain.TestRun{
+		TestPlanID: req.TestPlanID,
+		Name:       req.Name,
+		StartedBy:  userID.(uuid.UUID),
+	}
+
+	if err := h.testRunService.StartTestRun(c.Request.Context(), testRun); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, testRun)
+}
+
+func (h *TestRunHandler) GetTestRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test run")
+		return
+	}
+
+	testRun, ok := h.requireRunRole(c, id, domain.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, testRun)
+}
+
+func (h *TestRunHandler) ListTestRuns(c *gin.Context) {
+	testPlanID, err := uuid.Parse(c.Query("test_plan_id"))
+	if err != nil {
+		respondInvalidID(c, "test plan")
+		return
+	}
+
+	if !h.requirePlanRole(c, testPlanID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	testRuns, total, err := h.testRunService.ListTestRuns(c.Request.Context(), testPlanID, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  testRuns,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+type RecordTestResultRequest struct {
+	TestCaseID      *uuid.UUID `json:"test_case_id"`
+	ChecklistItemID *uuid.UUID `json:"checklist_item_id"`
+	Status          string     `json:"status" binding:"required,oneof=pass fail blocked skipped"`
+	Comments        string     `json:"comments"`
+}
+
+func (h *TestRunHandler) RecordTestResult(c *gin.Context) {
+	testRunID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test run")
+		return
+	}
+
+	var req RecordTestResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if _, ok := h.requireRunRole(c, testRunID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	executedBy := userID.(uuid.UUID)
+	result := &domain.TestResult{
+		TestRunID:       testRunID,
+		TestCaseID:      req.TestCaseID,
+		ChecklistItemID: req.ChecklistItemID,
+		Status:          req.Status,
+		Comments:        req.Comments,
+		ExecutedBy:      &executedBy,
+	}
+
+	if err := h.testRunService.RecordTestResult(c.Request.Context(), result); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *TestRunHandler) CompleteTestRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test run")
+		return
+	}
+
+	if _, ok := h.requireRunRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.testRunService.CompleteTestRun(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	testRun, err := h.testRunService.GetTestRun(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, testRun)
+}
+
+func (h *TestRunHandler) DeleteTestRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test run")
+		return
+	}
+
+	if _, ok := h.requireRunRole(c, id, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	if err := h.testRunService.DeleteTestRun(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test run deleted successfully"})
+}
+
+// IngestResults records a CI report (JUnit XML, go test -json or TAP) as a
+// completed test run of the plan. The report is sent either as the "file"
+// field of a multipart form or as the raw request body. format, name and
+// auto_create are read from the form or the query string; without a format
+// it is detected from the content.
+func (h *TestRunHandler) IngestResults(c *gin.Context) {
+	testPlanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test plan")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !h.requirePlanRole(c, testPlanID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	// Leave room for the multipart envelope around the report itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+1<<20)
+
+	var data []byte
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			respondError(c, errFileRequired)
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			respondStatus(c, http.StatusBadRequest, "invalid_file", err.Error())
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+		if err != nil {
+			respondStatus(c, http.StatusBadRequest, "invalid_file", err.Error())
+			return
+		}
+	} else {
+		data, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondStatus(c, http.StatusRequestEntityTooLarge, "file_too_large", "report is too large")
+			return
+		}
+	}
+	if int64(len(data)) > h.maxUploadSize {
+		respondStatus(c, http.StatusRequestEntityTooLarge, "file_too_large", "report is too large")
+		return
+	}
+	if len(data) == 0 {
+		respondError(c, domain.Validation("empty_report", "report is empty", domain.FieldError{Field: "file", Message: "is empty"}))
+		return
+	}
+
+	param := func(name string) string {
+		if value := c.PostForm(name); value != "" {
+			return value
+		}
+		return c.Query(name)
+	}
+
+	format := domain.ResultFormat(param("format"))
+	switch format {
+	case "":
+		format = domain.DetectResultFormat(data)
+	case domain.ResultFormatJUnit, domain.ResultFormatGoTest, domain.ResultFormatTAP:
+	default:
+		respondError(c, domain.Validation("unsupported_result_format", "format must be one of junit, gotest or tap",
+			domain.FieldError{Field: "format", Message: "must be one of junit, gotest or tap"}))
+		return
+	}
+	autoCreate, _ := strconv.ParseBool(param("auto_create"))
+
+	// Creating test cases goes beyond writing results, so API tokens need the admin scope for it
+	if scopes, ok := c.Get("apiTokenScopes"); ok && autoCreate && !scopes.(domain.TokenScopes).Allows(domain.TokenScopeAdmin) {
+		respondStatus(c, http.StatusForbidden, "insufficient_scope", "token lacks the admin scope needed for auto_create")
+		return
+	}
+
+	report, err := h.testRunService.IngestResults(c.Request.Context(), &domain.IngestRequest{
+		TestPlanID: testPlanID,
+		UserID:     userID.(uuid.UUID),
+		Format:     format,
+		Data:       data,
+		Name:       param("name"),
+		AutoCreate: autoCreate,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
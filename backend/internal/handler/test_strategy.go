@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TestStrategyHandler struct {
+	testStrategyService service.TestStrategyService
+	projectService      service.ProjectService
+}
+
+func NewTestStrategyHandler(testStrategyService service.TestStrategyService, projectService service.ProjectService) *TestStrategyHandler {
+	return &TestStrategyHandler{
+		testStrategyService: testStrategyService,
+		projectService:      projectService,
+	}
+}
+
+type TestStrategySectionsRequest struct {
+	Scope         string `json:"scope"`
+	Approach      string `json:"approach"`
+	Environments  string `json:"environments"`
+	Risks         string `json:"risks"`
+	EntryCriteria string `json:"entry_criteria"`
+	ExitCriteria  string `json:"exit_criteria"`
+}
+
+type CreateTestStrategyRequest struct {
+	ProjectID   uuid.UUID                   `json:"project_id" binding:"required"`
+	Name        string                      `json:"name" binding:"required"`
+	Description string                      `json:"description"`
+	Sections    TestStrategySectionsRequest `json:"sections"`
+	Content     string                      `json:"content"`
+}
+
+func (h *TestStrategyHandler) CreateTestStrategy(c *gin.Context) {
+	var req CreateTestStrategyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, errUnauthorized)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, req.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	strategy := &domain.TestStrategy{
+		ProjectID:   req.ProjectID,
+		Name:        req.Name,
+		Description: req.Description,
+		Sections: domain.TestStrategySections{
+			Scope:         req.Sections.Scope,
+			Approach:      req.Sections.Approach,
+			Environments:  req.Sections.Environments,
+			Risks:         req.Sections.Risks,
+			EntryCriteria: req.Sections.EntryCriteria,
+			ExitCriteria:  req.Sections.ExitCriteria,
+		},
+		Content:   req.Content,
+		CreatedBy: userID.(uuid.UUID),
+	}
+
+	if err := h.testStrategyService.CreateTestStrategy(c.Request.Context(), strategy); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, strategy.Version)
+	c.JSON(http.StatusCreated, strategy)
+}
+
+func (h *TestStrategyHandler) GetTestStrategy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test strategy")
+		return
+	}
+
+	strategy, err := h.testStrategyService.GetTestStrategy(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, strategy.ProjectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	setETag(c, strategy.Version)
+	c.JSON(http.StatusOK, strategy)
+}
+
+func (h *TestStrategyHandler) ListTestStrategies(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Query("project_id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	strategies, total, err := h.testStrategyService.ListTestStrategies(c.Request.Context(), projectID, page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  strategies,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+type UpdateTestStrategyRequest struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Sections    TestStrategySectionsRequest `json:"sections"`
+	Content     string                      `json:"content"`
+	// Version is the version being updated; If-Match takes precedence
+	Version *int64 `json:"version"`
+}
+
+func (h *TestStrategyHandler) UpdateTestStrategy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test strategy")
+		return
+	}
+
+	var req UpdateTestStrategyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	strategy, err := h.testStrategyService.GetTestStrategy(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, strategy.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	version, ok := requireVersion(c, req.Version, strategy.Version)
+	if !ok {
+		return
+	}
+	strategy.Version = version
+
+	if req.Name != "" {
+		strategy.Name = req.Name
+	}
+	if req.Description != "" {
+		strategy.Description = req.Description
+	}
+	if req.Content != "" {
+		strategy.Content = req.Content
+	}
+
+	// Update sections
+	if req.Sections.Scope != "" {
+		strategy.Sections.Scope = req.Sections.Scope
+	}
+	if req.Sections.Approach != "" {
+		strategy.Sections.Approach = req.Sections.Approach
+	}
+	if req.Sections.Environments != "" {
+		strategy.Sections.Environments = req.Sections.Environments
+	}
+	if req.Sections.Risks != "" {
+		strategy.Sections.Risks = req.Sections.Risks
+	}
+	if req.Sections.EntryCriteria != "" {
+		strategy.Sections.EntryCriteria = req.Sections.EntryCriteria
+	}
+	if req.Sections.ExitCriteria != "" {
+		strategy.Sections.ExitCriteria = req.Sections.ExitCriteria
+	}
+
+	if err := h.testStrategyService.UpdateTestStrategy(c.Request.Context(), strategy); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	setETag(c, strategy.Version)
+	c.JSON(http.StatusOK, strategy)
+}
+
+func (h *TestStrategyHandler) DeleteTestStrategy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "test strategy")
+		return
+	}
+
+	strategy, err := h.testStrategyService.GetTestStrategy(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, strategy.ProjectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	if err := h.testStrategyService.DeleteTestStrategy(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Test strategy deleted successfully"})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TrashHandler struct {
+	trashService   service.TrashService
+	projectService service.ProjectService
+}
+
+func NewTrashHandler(trashService service.TrashService, projectService service.ProjectService) *TrashHandler {
+	return &TrashHandler{
+		trashService:   trashService,
+		projectService: projectService,
+	}
+}
+
+// ListTrash lists the deleted entities of the project, most recently deleted
+// first, optionally filtered by entity_type.
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleViewer) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	items, total, err := h.trashService.ListTrash(c.Request.Context(), projectID, c.Query("entity_type"), page, size)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  items,
+		"total": total,
+		"page":  page,
+		"size":  size,
+	})
+}
+
+func (h *TrashHandler) RestoreItem(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondInvalidID(c, "project")
+		return
+	}
+
+	entityID, err := uuid.Parse(c.Param("entityId"))
+	if err != nil {
+		respondInvalidID(c, "entity")
+		return
+	}
+
+	if !requireProjectRole(c, h.projectService, projectID, domain.ProjectRoleEditor) {
+		return
+	}
+
+	if err := h.trashService.RestoreItem(c.Request.Context(), projectID, c.Param("entityType"), entityID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item restored successfully"})
+}
+
+// Purge permanently removes entities that have been in the trash for longer
+// than older_than_days, which defaults to the configured retention period.
+func (h *TrashHandler) Purge(c *gin.Context) {
+	olderThan := h.trashService.Retention()
+	if value := c.Query("older_than_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			respondError(c, domain.Validation("invalid_retention", "older_than_days must be a non-negative number of days",
+				domain.FieldError{Field: "older_than_days", Message: "must be a non-negative integer"}))
+			return
+		}
+		olderThan = time.Duration(days) * 24 * time.Hour
+	}
+
+	report, err := h.trashService.Purge(c.Request.Context(), olderThan)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -7,28 +7,65 @@ import (
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/AntVerkh/test-management-system/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+// AuthMiddleware authenticates requests with either a login access token or
+// an API token. API tokens need the read scope for safe requests and
+// writeScope for any other request, so route groups choose what a token must
+// hold to change data through them.
+func AuthMiddleware(authService service.AuthService, tokenService service.APITokenService, writeScope domain.TokenScope) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required", "code": "unauthorized"})
 			c.Abort()
 			return
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		user, err := authService.ValidateToken(c.Request.Context(), token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
-			return
+
+		var user *domain.User
+		if domain.IsAPIToken(token) {
+			var apiToken *domain.APIToken
+			var err error
+			user, apiToken, err = tokenService.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token", "code": "invalid_token"})
+				c.Abort()
+				return
+			}
+
+			required := writeScope
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				required = domain.TokenScopeRead
+			}
+			if !apiToken.Scopes.Allows(required) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "token lacks the " + string(required) + " scope", "code": "insufficient_scope"})
+				c.Abort()
+				return
+			}
+
+			c.Set("apiTokenID", apiToken.ID)
+			c.Set("apiTokenScopes", apiToken.Scopes)
+		} else {
+			var sessionID uuid.UUID
+			var err error
+			user, sessionID, err = authService.ValidateToken(c.Request.Context(), token)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token", "code": "invalid_token"})
+				c.Abort()
+				return
+			}
+
+			c.Set("sessionID", sessionID)
 		}
 
 		c.Set("user", user)
 		c.Set("userID", user.ID)
 		c.Set("userRole", user.Role)
+		c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), user.ID))
 		c.Next()
 	}
 }
@@ -37,7 +74,7 @@ func RoleMiddleware(allowedRoles ...domain.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("userRole")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "code": "unauthorized"})
 			c.Abort()
 			return
 		}
@@ -49,7 +86,7 @@ func RoleMiddleware(allowedRoles ...domain.UserRole) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": "insufficient_permissions"})
 		c.Abort()
 	}
 }
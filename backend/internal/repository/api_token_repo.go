@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *domain.APIToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.APIToken, error)
+	GetByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	// List returns the tokens of a user, or of all users when userID is uuid.Nil, newest first.
+	List(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	return dbError(conn(ctx, r.db).Create(token).Error, "token")
+}
+
+func (r *apiTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.APIToken, error) {
+	var token domain.APIToken
+	err := conn(ctx, r.db).First(&token, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "token")
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	err := conn(ctx, r.db).First(&token, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, dbError(err, "token")
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error) {
+	var tokens []domain.APIToken
+	query := conn(ctx, r.db)
+	if userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *apiTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return conn(ctx, r.db).Model(&domain.APIToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
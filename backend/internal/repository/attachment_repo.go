@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return conn(ctx, r.db).Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := conn(ctx, r.db).First(&attachment, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "attachment")
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&domain.Attachment{}, "id = ?", id).Error
+}
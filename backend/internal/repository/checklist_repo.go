@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -16,19 +17,36 @@ func NewChecklistRepository(db *gorm.DB) ChecklistRepository {
 }
 
 func (r *checklistRepository) Create(ctx context.Context, checklist *domain.Checklist) error {
-	return r.db.WithContext(ctx).Create(checklist).Error
+	return conn(ctx, r.db).Create(checklist).Error
 }
 
 func (r *checklistRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Checklist, error) {
 	var checklist domain.Checklist
-	err := r.db.WithContext(ctx).
-		Preload("Items").
+	err := conn(ctx, r.db).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order(`"order" ASC`)
+		}).
 		First(&checklist, "id = ?", id).Error
-	return &checklist, err
+	if err != nil {
+		return nil, dbError(err, "checklist")
+	}
+	return &checklist, nil
 }
 
+// Update saves the checklist itself if it is still at checklist.Version; items
+// are managed through the item methods.
 func (r *checklistRepository) Update(ctx context.Context, checklist *domain.Checklist) error {
-	return r.db.WithContext(ctx).Save(checklist).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return saveVersioned(tx, &domain.Checklist{}, checklist.ID, &checklist.Version, "checklist", func() error {
+			return tx.Omit("Items").Save(checklist).Error
+		})
+	})
+}
+
+// Delete moves the checklist to the trash. Its items stay, so results of past
+// runs keep referring to them.
+func (r *checklistRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return softDelete(conn(ctx, r.db), &domain.Checklist{}, id, deletedBy, "checklist")
 }
 
 func (r *checklistRepository) List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.Checklist, int64, error) {
@@ -37,7 +55,7 @@ func (r *checklistRepository) List(ctx context.Context, projectID uuid.UUID, pag
 
 	offset := (page - 1) * size
 
-	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	query := conn(ctx, r.db).Where("project_id = ?", projectID)
 
 	err := query.Model(&domain.Checklist{}).Count(&total).Error
 	if err != nil {
@@ -47,3 +65,70 @@ func (r *checklistRepository) List(ctx context.Context, projectID uuid.UUID, pag
 	err = query.Offset(offset).Limit(size).Order("created_at DESC").Find(&checklists).Error
 	return checklists, total, err
 }
+
+func (r *checklistRepository) GetItem(ctx context.Context, id uuid.UUID) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	err := conn(ctx, r.db).First(&item, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "checklist item")
+	}
+	return &item, nil
+}
+
+// AddItem inserts the item at item.Order, shifting the following items down.
+func (r *checklistRepository) AddItem(ctx context.Context, item *domain.ChecklistItem) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.ChecklistItem{}).
+			Where(`checklist_id = ? AND "order" >= ?`, item.ChecklistID, item.Order).
+			Update("order", gorm.Expr(`"order" + 1`)).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.Checklist{}, item.ChecklistID)
+	})
+}
+
+func (r *checklistRepository) UpdateItem(ctx context.Context, item *domain.ChecklistItem) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(item).Error; err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.Checklist{}, item.ChecklistID)
+	})
+}
+
+// DeleteItem removes the item and closes the gap it leaves in the ordering.
+func (r *checklistRepository) DeleteItem(ctx context.Context, item *domain.ChecklistItem) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.ChecklistItem{}, "id = ?", item.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&domain.ChecklistItem{}).
+			Where(`checklist_id = ? AND "order" > ?`, item.ChecklistID, item.Order).
+			Update("order", gorm.Expr(`"order" - 1`)).Error
+		if err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.Checklist{}, item.ChecklistID)
+	})
+}
+
+// ReorderItems assigns contiguous 1-based positions following the order of itemIDs.
+func (r *checklistRepository) ReorderItems(ctx context.Context, checklistID uuid.UUID, itemIDs []uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for i, itemID := range itemIDs {
+			err := tx.Model(&domain.ChecklistItem{}).
+				Where("id = ? AND checklist_id = ?", itemID, checklistID).
+				Update("order", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return touchVersion(tx, &domain.Checklist{}, checklistID)
+	})
+}
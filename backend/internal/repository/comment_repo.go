@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *domain.Comment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Comment, error)
+	Update(ctx context.Context, comment *domain.Comment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, entityType string, entityID uuid.UUID, resolved *bool, page, size int) ([]domain.Comment, int64, error)
+	ListAll(ctx context.Context, entityType string, entityID uuid.UUID) ([]domain.Comment, error)
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return conn(ctx, r.db).Omit("User", "Replies").Create(comment).Error
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Comment, error) {
+	var comment domain.Comment
+	err := conn(ctx, r.db).
+		Preload("User").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Replies.User").
+		First(&comment, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "comment")
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return conn(ctx, r.db).Omit("User", "Replies").Save(comment).Error
+}
+
+// Delete removes a comment together with its replies.
+func (r *commentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("parent_id = ?", id).Delete(&domain.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Comment{}, "id = ?", id).Error
+	})
+}
+
+// List returns a page of top-level threads with their replies, newest thread first.
+func (r *commentRepository) List(ctx context.Context, entityType string, entityID uuid.UUID, resolved *bool, page, size int) ([]domain.Comment, int64, error) {
+	var comments []domain.Comment
+	var total int64
+
+	offset := (page - 1) * size
+
+	query := conn(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ? AND parent_id IS NULL", entityType, entityID)
+	if resolved != nil {
+		query = query.Where("resolved = ?", *resolved)
+	}
+
+	err := query.Model(&domain.Comment{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.
+		Preload("User").
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Replies.User").
+		Offset(offset).Limit(size).Order("created_at DESC").Find(&comments).Error
+	return comments, total, err
+}
+
+// ListAll returns every comment on an entity in chronological order.
+func (r *commentRepository) ListAll(ctx context.Context, entityType string, entityID uuid.UUID) ([]domain.Comment, error) {
+	var comments []domain.Comment
+	err := conn(ctx, r.db).
+		Preload("User").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	return comments, err
+}
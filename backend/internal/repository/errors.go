@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"gorm.io/gorm"
+)
+
+// dbError converts GORM errors into domain errors so services and handlers
+// do not depend on GORM. entity names the record in words ("test plan").
+func dbError(err error, entity string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.NotFound(entity)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.Conflict(strings.ReplaceAll(entity, " ", "_")+"_exists", entity+" already exists")
+	}
+	return err
+}
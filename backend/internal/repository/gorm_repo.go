@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -15,17 +18,48 @@ func NewGormRepository(db *gorm.DB) *GormRepository {
 }
 
 func (r *GormRepository) Create(ctx context.Context, entity interface{}) error {
-	return r.db.WithContext(ctx).Create(entity).Error
+	return conn(ctx, r.db).Create(entity).Error
 }
 
 func (r *GormRepository) Update(ctx context.Context, entity interface{}) error {
-	return r.db.WithContext(ctx).Save(entity).Error
+	return conn(ctx, r.db).Save(entity).Error
 }
 
 func (r *GormRepository) Delete(ctx context.Context, entity interface{}) error {
-	return r.db.WithContext(ctx).Delete(entity).Error
+	return conn(ctx, r.db).Delete(entity).Error
 }
 
 func (r *GormRepository) FindByID(ctx context.Context, id uint, entity interface{}) error {
-	return r.db.WithContext(ctx).First(entity, id).Error
+	return conn(ctx, r.db).First(entity, id).Error
+}
+
+// saveVersioned saves an entity that carries a version, in tx. The row moves
+// from version to version+1 only if it is still at version, which also locks
+// it until tx ends; otherwise the save fails with a version conflict. On
+// success *version is the new version.
+func saveVersioned(tx *gorm.DB, model interface{}, id uuid.UUID, version *int64, entity string, save func() error) error {
+	result := tx.Model(model).Where("id = ? AND version = ?", id, *version).UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.VersionConflict(entity, nil)
+	}
+
+	*version++
+	if err := save(); err != nil {
+		*version--
+		return err
+	}
+	return nil
+}
+
+// touchVersion advances the version of the row by one in tx, for changes to
+// parts of an entity that are saved on their own, so that updates based on
+// the previous version fail.
+func touchVersion(tx *gorm.DB, model interface{}, id uuid.UUID) error {
+	return tx.Model(model).Where("id = ?", id).Updates(map[string]interface{}{
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": time.Now(),
+	}).Error
 }
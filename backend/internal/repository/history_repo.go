@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type HistoryRepository interface {
+	Create(ctx context.Context, history *domain.History) error
+	List(ctx context.Context, entityType string, entityID uuid.UUID, page, size int) ([]domain.History, int64, error)
+	ListAll(ctx context.Context, entityType string, entityID uuid.UUID) ([]domain.History, error)
+}
+
+type historyRepository struct {
+	db *gorm.DB
+}
+
+func NewHistoryRepository(db *gorm.DB) HistoryRepository {
+	return &historyRepository{db: db}
+}
+
+func (r *historyRepository) Create(ctx context.Context, history *domain.History) error {
+	return conn(ctx, r.db).Omit("User").Create(history).Error
+}
+
+func (r *historyRepository) List(ctx context.Context, entityType string, entityID uuid.UUID, page, size int) ([]domain.History, int64, error) {
+	var entries []domain.History
+	var total int64
+
+	offset := (page - 1) * size
+
+	query := conn(ctx, r.db).Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+
+	err := query.Model(&domain.History{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Preload("User").Offset(offset).Limit(size).Order("changed_at DESC").Find(&entries).Error
+	return entries, total, err
+}
+
+// ListAll returns the complete history of an entity in chronological order.
+func (r *historyRepository) ListAll(ctx context.Context, entityType string, entityID uuid.UUID) ([]domain.History, error) {
+	var entries []domain.History
+	err := conn(ctx, r.db).
+		Preload("User").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("changed_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	// Create stores the invitation and revokes earlier pending invitations for the same email.
+	Create(ctx context.Context, invitation *domain.Invitation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error)
+	GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error)
+	List(ctx context.Context) ([]domain.Invitation, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Accept creates the invited user and marks the invitation accepted, unless
+	// it was accepted or revoked in the meantime.
+	Accept(ctx context.Context, invitation *domain.Invitation, user *domain.User) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Invitation{}).
+			Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.Email).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		return dbError(tx.Create(invitation).Error, "invitation")
+	})
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := conn(ctx, r.db).First(&invitation, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "invitation")
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	err := conn(ctx, r.db).First(&invitation, "token_hash = ?", hash).Error
+	if err != nil {
+		return nil, dbError(err, "invitation")
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) List(ctx context.Context) ([]domain.Invitation, error) {
+	var invitations []domain.Invitation
+	err := conn(ctx, r.db).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *invitationRepository) Accept(ctx context.Context, invitation *domain.Invitation, user *domain.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&domain.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.Conflict("invitation_not_pending", "invitation has already been used or revoked")
+		}
+		invitation.AcceptedAt = &now
+
+		return dbError(tx.Create(user).Error, "user")
+	})
+}
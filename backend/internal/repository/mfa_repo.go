@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	GetEnrollment(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error)
+	// SaveEnrollment creates the user's enrollment or replaces the existing one.
+	SaveEnrollment(ctx context.Context, enrollment *domain.MFAEnrollment) error
+	// ConfirmEnrollment puts the enrollment in effect together with its first recovery codes.
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, confirmedAt time.Time, codes []domain.MFARecoveryCode) error
+	// DeleteEnrollment removes the enrollment and the user's recovery codes.
+	DeleteEnrollment(ctx context.Context, userID uuid.UUID) error
+	// UseStep records the time step of an accepted code. A step at or before
+	// the last one recorded is reported as NotFound, so a code works once.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// RecordFailure counts an invalid code; the maxAttempts-th failure in a row
+	// locks the enrollment until lockUntil and starts counting again.
+	RecordFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time) error
+	ClearFailures(ctx context.Context, userID uuid.UUID) error
+
+	// ReplaceRecoveryCodes invalidates the user's recovery codes and stores new ones.
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.MFARecoveryCode) error
+	// UseRecoveryCode marks an unused code as used; unknown and used codes are NotFound.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	GetPolicy(ctx context.Context, role domain.UserRole) (*domain.MFAPolicy, error)
+	ListPolicies(ctx context.Context) ([]domain.MFAPolicy, error)
+	SavePolicy(ctx context.Context, policy *domain.MFAPolicy) error
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) GetEnrollment(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error) {
+	var enrollment domain.MFAEnrollment
+	err := conn(ctx, r.db).First(&enrollment, "user_id = ?", userID).Error
+	if err != nil {
+		return nil, dbError(err, "mfa enrollment")
+	}
+	return &enrollment, nil
+}
+
+func (r *mfaRepository) SaveEnrollment(ctx context.Context, enrollment *domain.MFAEnrollment) error {
+	return conn(ctx, r.db).Save(enrollment).Error
+}
+
+func (r *mfaRepository) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, confirmedAt time.Time, codes []domain.MFARecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.MFAEnrollment{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Update("confirmed_at", confirmedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NotFound("mfa enrollment")
+		}
+
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func (r *mfaRepository) DeleteEnrollment(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.MFAEnrollment{}).Error
+	})
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	// Comparing in the update keeps two concurrent requests from using the same code
+	result := conn(ctx, r.db).Model(&domain.MFAEnrollment{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("mfa code")
+	}
+	return nil
+}
+
+func (r *mfaRepository) RecordFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time) error {
+	return conn(ctx, r.db).Model(&domain.MFAEnrollment{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", maxAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockUntil),
+		}).Error
+}
+
+func (r *mfaRepository) ClearFailures(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.MFAEnrollment{}).
+		Where("user_id = ?", userID).
+		Update("failed_attempts", 0).Error
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []domain.MFARecoveryCode) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []domain.MFARecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error {
+	result := conn(ctx, r.db).Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("recovery code")
+	}
+	return nil
+}
+
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *mfaRepository) GetPolicy(ctx context.Context, role domain.UserRole) (*domain.MFAPolicy, error) {
+	var policy domain.MFAPolicy
+	err := conn(ctx, r.db).First(&policy, "role = ?", role).Error
+	if err != nil {
+		return nil, dbError(err, "mfa policy")
+	}
+	return &policy, nil
+}
+
+func (r *mfaRepository) ListPolicies(ctx context.Context) ([]domain.MFAPolicy, error) {
+	var policies []domain.MFAPolicy
+	err := conn(ctx, r.db).Order("role").Find(&policies).Error
+	return policies, err
+}
+
+func (r *mfaRepository) SavePolicy(ctx context.Context, policy *domain.MFAPolicy) error {
+	return conn(ctx, r.db).Save(policy).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCAuthRequestRepository interface {
+	// Create stores the request and deletes expired ones, which are left
+	// behind by sign-ins that were never completed.
+	Create(ctx context.Context, request *domain.OIDCAuthRequest) error
+	// Consume deletes an unexpired request and returns it. Unknown, used and
+	// expired requests are all reported as NotFound.
+	Consume(ctx context.Context, stateHash string) (*domain.OIDCAuthRequest, error)
+}
+
+type oidcAuthRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCAuthRequestRepository(db *gorm.DB) OIDCAuthRequestRepository {
+	return &oidcAuthRequestRepository{db: db}
+}
+
+func (r *oidcAuthRequestRepository) Create(ctx context.Context, request *domain.OIDCAuthRequest) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&domain.OIDCAuthRequest{}).Error; err != nil {
+			return err
+		}
+
+		return dbError(tx.Create(request).Error, "sign in request")
+	})
+}
+
+func (r *oidcAuthRequestRepository) Consume(ctx context.Context, stateHash string) (*domain.OIDCAuthRequest, error) {
+	var request domain.OIDCAuthRequest
+
+	// Deleting as the check keeps a callback from being completed twice
+	result := conn(ctx, r.db).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ? AND expires_at > ?", stateHash, time.Now()).
+		Delete(&request)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.NotFound("sign in request")
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProjectRepository interface {
+	Create(ctx context.Context, project *domain.Project) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error)
+	Update(ctx context.Context, project *domain.Project) error
+	List(ctx context.Context, page, size int) ([]domain.Project, int64, error)
+	ListByMember(ctx context.Context, userID uuid.UUID, page, size int) ([]domain.Project, int64, error)
+	GetMember(ctx context.Context, projectID, userID uuid.UUID) (*domain.ProjectMember, error)
+	ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error)
+	SaveMember(ctx context.Context, member *domain.ProjectMember) error
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error
+	CountOwners(ctx context.Context, projectID uuid.UUID) (int64, error)
+	GetEntityProjectID(ctx context.Context, entityType string, entityID uuid.UUID) (uuid.UUID, error)
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+// Create stores the project together with its initial members in one transaction.
+func (r *projectRepository) Create(ctx context.Context, project *domain.Project) error {
+	return conn(ctx, r.db).Create(project).Error
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	var project domain.Project
+	err := conn(ctx, r.db).First(&project, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "project")
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *domain.Project) error {
+	return conn(ctx, r.db).Omit("Members").Save(project).Error
+}
+
+func (r *projectRepository) List(ctx context.Context, page, size int) ([]domain.Project, int64, error) {
+	var projects []domain.Project
+	var total int64
+
+	offset := (page - 1) * size
+
+	query := conn(ctx, r.db).Model(&domain.Project{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Offset(offset).Limit(size).Order("created_at DESC").Find(&projects).Error
+	return projects, total, err
+}
+
+func (r *projectRepository) ListByMember(ctx context.Context, userID uuid.UUID, page, size int) ([]domain.Project, int64, error) {
+	var projects []domain.Project
+	var total int64
+
+	offset := (page - 1) * size
+
+	query := conn(ctx, r.db).Model(&domain.Project{}).
+		Joins("JOIN project_members ON project_members.project_id = projects.id").
+		Where("project_members.user_id = ?", userID)
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Offset(offset).Limit(size).Order("projects.created_at DESC").Find(&projects).Error
+	return projects, total, err
+}
+
+func (r *projectRepository) GetMember(ctx context.Context, projectID, userID uuid.UUID) (*domain.ProjectMember, error) {
+	var member domain.ProjectMember
+	err := conn(ctx, r.db).
+		First(&member, "project_id = ? AND user_id = ?", projectID, userID).Error
+	if err != nil {
+		return nil, dbError(err, "project member")
+	}
+	return &member, nil
+}
+
+func (r *projectRepository) ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	var members []domain.ProjectMember
+	err := conn(ctx, r.db).
+		Preload("User").
+		Where("project_id = ?", projectID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *projectRepository) SaveMember(ctx context.Context, member *domain.ProjectMember) error {
+	return conn(ctx, r.db).Omit("User").Save(member).Error
+}
+
+func (r *projectRepository) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	return conn(ctx, r.db).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Delete(&domain.ProjectMember{}).Error
+}
+
+func (r *projectRepository) CountOwners(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&domain.ProjectMember{}).
+		Where("project_id = ? AND role = ?", projectID, domain.ProjectRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+// GetEntityProjectID resolves the project an entity belongs to. Test runs and
+// results have no project column and are resolved through their test plan.
+func (r *projectRepository) GetEntityProjectID(ctx context.Context, entityType string, entityID uuid.UUID) (uuid.UUID, error) {
+	var projectIDs []uuid.UUID
+	query := conn(ctx, r.db)
+	column := "project_id"
+
+	switch entityType {
+	case domain.EntityTypeTestPlan:
+		query = query.Model(&domain.TestPlan{}).Where("id = ?", entityID)
+	case domain.EntityTypeTestCase:
+		query = query.Model(&domain.TestCase{}).Where("id = ?", entityID)
+	case domain.EntityTypeChecklist:
+		query = query.Model(&domain.Checklist{}).Where("id = ?", entityID)
+	case domain.EntityTypeTestStrategy:
+		query = query.Model(&domain.TestStrategy{}).Where("id = ?", entityID)
+	case domain.EntityTypeTestRun:
+		query = query.Model(&domain.TestRun{}).
+			Joins("JOIN test_plans ON test_plans.id = test_runs.test_plan_id").
+			Where("test_runs.id = ?", entityID)
+		column = "test_plans.project_id"
+	case domain.EntityTypeTestResult:
+		query = query.Model(&domain.TestResult{}).
+			Joins("JOIN test_runs ON test_runs.id = test_results.test_run_id").
+			Joins("JOIN test_plans ON test_plans.id = test_runs.test_plan_id").
+			Where("test_results.id = ?", entityID)
+		column = "test_plans.project_id"
+	default:
+		return uuid.Nil, domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+
+	if err := query.Limit(1).Pluck(column, &projectIDs).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(projectIDs) == 0 {
+		return uuid.Nil, domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+
+	return projectIDs[0], nil
+}
@@ -10,22 +10,38 @@ import (
 // Add these repository interfaces
 type TestCaseRepository interface {
 	Create(ctx context.Context, testCase *domain.TestCase) error
+	CreateBatch(ctx context.Context, testCases []domain.TestCase) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.TestCase, error)
 	Update(ctx context.Context, testCase *domain.TestCase) error
-	List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestCase, int64, error)
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error
+	List(ctx context.Context, projectID uuid.UUID, filter domain.TestCaseFilter, page, size int) ([]domain.TestCase, int64, error)
+	FindAutomationIDs(ctx context.Context, projectID uuid.UUID, keys []string) ([]domain.TestCaseAutomationID, error)
+	GetAutomationCoverage(ctx context.Context, projectID uuid.UUID) (*domain.AutomationCoverage, error)
+	GetStep(ctx context.Context, id uuid.UUID) (*domain.TestStep, error)
+	AddStep(ctx context.Context, step *domain.TestStep) error
+	UpdateStep(ctx context.Context, step *domain.TestStep) error
+	DeleteStep(ctx context.Context, step *domain.TestStep) error
+	MoveStep(ctx context.Context, step *domain.TestStep, order int) error
 }
 
 type ChecklistRepository interface {
 	Create(ctx context.Context, checklist *domain.Checklist) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Checklist, error)
 	Update(ctx context.Context, checklist *domain.Checklist) error
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error
 	List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.Checklist, int64, error)
+	GetItem(ctx context.Context, id uuid.UUID) (*domain.ChecklistItem, error)
+	AddItem(ctx context.Context, item *domain.ChecklistItem) error
+	UpdateItem(ctx context.Context, item *domain.ChecklistItem) error
+	DeleteItem(ctx context.Context, item *domain.ChecklistItem) error
+	ReorderItems(ctx context.Context, checklistID uuid.UUID, itemIDs []uuid.UUID) error
 }
 
 type TestStrategyRepository interface {
 	Create(ctx context.Context, strategy *domain.TestStrategy) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.TestStrategy, error)
 	Update(ctx context.Context, strategy *domain.TestStrategy) error
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error
 	List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestStrategy, int64, error)
 }
 
@@ -33,6 +49,10 @@ type TestRunRepository interface {
 	Create(ctx context.Context, testRun *domain.TestRun) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.TestRun, error)
 	Update(ctx context.Context, testRun *domain.TestRun) error
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error
 	List(ctx context.Context, testPlanID uuid.UUID, page, size int) ([]domain.TestRun, int64, error)
 	Complete(ctx context.Context, id uuid.UUID) error
+	GetResult(ctx context.Context, testRunID uuid.UUID, testCaseID, checklistItemID *uuid.UUID) (*domain.TestResult, error)
+	GetResultByID(ctx context.Context, id uuid.UUID) (*domain.TestResult, error)
+	UpdateResult(ctx context.Context, result *domain.TestResult) error
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ServiceAccountRepository interface {
+	// Create stores the account together with its user and its membership of the project.
+	Create(ctx context.Context, account *domain.ServiceAccount, user *domain.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error)
+	ListByProject(ctx context.Context, projectID uuid.UUID) ([]domain.ServiceAccount, error)
+	// Delete revokes the account's tokens and removes it from its project. The
+	// user row is kept because history and results still refer to it.
+	Delete(ctx context.Context, account *domain.ServiceAccount) error
+}
+
+type serviceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountRepository{db: db}
+}
+
+func (r *serviceAccountRepository) Create(ctx context.Context, account *domain.ServiceAccount, user *domain.User) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return dbError(err, "user")
+		}
+
+		member := &domain.ProjectMember{
+			ProjectID: account.ProjectID,
+			UserID:    user.ID,
+			Role:      account.Role,
+			CreatedAt: account.CreatedAt,
+		}
+		if err := tx.Omit("User").Create(member).Error; err != nil {
+			return dbError(err, "project member")
+		}
+
+		return dbError(tx.Create(account).Error, "service account")
+	})
+}
+
+func (r *serviceAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	err := r.withRole(conn(ctx, r.db)).
+		First(&account, "service_accounts.id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "service account")
+	}
+	return &account, nil
+}
+
+func (r *serviceAccountRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]domain.ServiceAccount, error) {
+	var accounts []domain.ServiceAccount
+	err := r.withRole(conn(ctx, r.db)).
+		Where("service_accounts.project_id = ?", projectID).
+		Order("service_accounts.name ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *serviceAccountRepository) Delete(ctx context.Context, account *domain.ServiceAccount) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.APIToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", account.UserID).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("project_id = ? AND user_id = ?", account.ProjectID, account.UserID).
+			Delete(&domain.ProjectMember{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&domain.ServiceAccount{}, "id = ?", account.ID).Error
+	})
+}
+
+// withRole selects the account's role from its project membership.
+func (r *serviceAccountRepository) withRole(db *gorm.DB) *gorm.DB {
+	return db.Model(&domain.ServiceAccount{}).
+		Select("service_accounts.*, project_members.role AS role").
+		Joins("LEFT JOIN project_members ON project_members.project_id = service_accounts.project_id AND project_members.user_id = service_accounts.user_id")
+}
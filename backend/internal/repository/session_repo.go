@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Session, error)
+	// GetByTokenHash finds the session whose current or previous refresh token has the hash.
+	GetByTokenHash(ctx context.Context, hash string) (*domain.Session, error)
+	// Rotate replaces the refresh token of an active session, provided it is
+	// still oldHash; a concurrent rotation makes it return NotFound.
+	Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, expiresAt time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	// DeleteInactive removes the user's sessions that were revoked or expired before the given time.
+	DeleteInactive(ctx context.Context, userID uuid.UUID, before time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return dbError(conn(ctx, r.db).Create(session).Error, "session")
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	var session domain.Session
+	err := conn(ctx, r.db).First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "session")
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.Session, error) {
+	var session domain.Session
+	err := conn(ctx, r.db).
+		Where("refresh_token_hash = ? OR previous_token_hash = ?", hash, hash).
+		First(&session).Error
+	if err != nil {
+		return nil, dbError(err, "session")
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) Rotate(ctx context.Context, id uuid.UUID, oldHash, newHash string, expiresAt time.Time) error {
+	result := conn(ctx, r.db).Model(&domain.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  newHash,
+			"previous_token_hash": oldHash,
+			"expires_at":          expiresAt,
+			"last_used_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return dbError(result.Error, "session")
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound("session")
+	}
+	return nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) DeleteInactive(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	return conn(ctx, r.db).
+		Where("user_id = ? AND (revoked_at < ? OR expires_at < ?)", userID, before, before).
+		Delete(&domain.Session{}).Error
+}
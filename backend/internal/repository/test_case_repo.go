@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"math"
+	"strings"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type testCaseRepository struct {
@@ -16,36 +19,268 @@ func NewTestCaseRepository(db *gorm.DB) TestCaseRepository {
 	return &testCaseRepository{db: db}
 }
 
+// Create stores the test case. A duplicate key can only come from an automation
+// identifier used concurrently by another test case.
 func (r *testCaseRepository) Create(ctx context.Context, testCase *domain.TestCase) error {
-	return r.db.WithContext(ctx).Create(testCase).Error
+	return dbError(conn(ctx, r.db).Create(testCase).Error, "automation identifier")
+}
+
+// CreateBatch stores the test cases and their steps in one transaction, so
+// either all of them are created or none are.
+func (r *testCaseRepository) CreateBatch(ctx context.Context, testCases []domain.TestCase) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for i := range testCases {
+			if err := tx.Create(&testCases[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return dbError(err, "automation identifier")
 }
 
 func (r *testCaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TestCase, error) {
 	var testCase domain.TestCase
-	err := r.db.WithContext(ctx).
-		Preload("Steps").
+	err := conn(ctx, r.db).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order(`"order" ASC`)
+		}).
+		Preload("AutomationIDs").
 		Preload("Attachments").
 		First(&testCase, "id = ?", id).Error
-	return &testCase, err
+	if err != nil {
+		return nil, dbError(err, "test case")
+	}
+	return &testCase, nil
 }
 
+// Update saves the test case if it is still at testCase.Version. Its steps
+// are replaced by testCase.Steps: steps left out are deleted and the others
+// are inserted or updated. Automation identifiers removed from the test case
+// are deleted as well, all in the same transaction.
 func (r *testCaseRepository) Update(ctx context.Context, testCase *domain.TestCase) error {
-	return r.db.WithContext(ctx).Save(testCase).Error
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return saveVersioned(tx, &domain.TestCase{}, testCase.ID, &testCase.Version, "test case", func() error {
+			keepSteps := make([]uuid.UUID, 0, len(testCase.Steps))
+			for _, step := range testCase.Steps {
+				keepSteps = append(keepSteps, step.ID)
+			}
+			if err := deleteOthers(tx, &domain.TestStep{}, testCase.ID, keepSteps); err != nil {
+				return err
+			}
+			if len(testCase.Steps) > 0 {
+				err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&testCase.Steps).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			keepIDs := make([]uuid.UUID, 0, len(testCase.AutomationIDs))
+			for _, id := range testCase.AutomationIDs {
+				keepIDs = append(keepIDs, id.ID)
+			}
+			if err := deleteOthers(tx, &domain.TestCaseAutomationID{}, testCase.ID, keepIDs); err != nil {
+				return err
+			}
+
+			return tx.Omit("Steps").Save(testCase).Error
+		})
+	})
+	return dbError(err, "automation identifier")
 }
 
-func (r *testCaseRepository) List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestCase, int64, error) {
+// Delete moves the test case to the trash. Test plans no longer list it, while
+// results of past runs keep referring to it.
+func (r *testCaseRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return softDelete(conn(ctx, r.db), &domain.TestCase{}, id, deletedBy, "test case")
+}
+
+// deleteOthers deletes the rows of model belonging to the test case whose ID
+// is not in keep.
+func deleteOthers(tx *gorm.DB, model interface{}, testCaseID uuid.UUID, keep []uuid.UUID) error {
+	query := tx.Where("test_case_id = ?", testCaseID)
+	if len(keep) > 0 {
+		query = query.Where("id NOT IN ?", keep)
+	}
+	return query.Delete(model).Error
+}
+
+func (r *testCaseRepository) List(ctx context.Context, projectID uuid.UUID, filter domain.TestCaseFilter, page, size int) ([]domain.TestCase, int64, error) {
 	var testCases []domain.TestCase
 	var total int64
 
 	offset := (page - 1) * size
 
-	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	query := conn(ctx, r.db).Where("project_id = ?", projectID)
+	if filter.AutomationStatus != "" {
+		query = query.Where("automation_status = ?", filter.AutomationStatus)
+	}
+	if filter.AutomationID != "" {
+		query = query.Where(
+			`id IN (SELECT test_case_id FROM test_case_automation_ids WHERE project_id = ? AND key ILIKE ? ESCAPE '\')`,
+			projectID, "%"+escapeLike(filter.AutomationID)+"%",
+		)
+	}
 
 	err := query.Model(&domain.TestCase{}).Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = query.Offset(offset).Limit(size).Order("created_at DESC").Find(&testCases).Error
+	err = query.
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order(`"order" ASC`)
+		}).
+		Preload("AutomationIDs").
+		// Imports give a whole batch one creation time; the ID keeps pages stable
+		Offset(offset).Limit(size).Order("created_at DESC, id").Find(&testCases).Error
 	return testCases, total, err
 }
+
+// FindAutomationIDs returns the automation identifiers of the project whose
+// key is one of keys.
+func (r *testCaseRepository) FindAutomationIDs(ctx context.Context, projectID uuid.UUID, keys []string) ([]domain.TestCaseAutomationID, error) {
+	var ids []domain.TestCaseAutomationID
+
+	// Query in batches to stay well below the bind parameter limit
+	const batchSize = 500
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var batch []domain.TestCaseAutomationID
+		err := conn(ctx, r.db).
+			Where("project_id = ? AND key IN ?", projectID, keys[start:end]).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, batch...)
+	}
+	return ids, nil
+}
+
+func (r *testCaseRepository) GetAutomationCoverage(ctx context.Context, projectID uuid.UUID) (*domain.AutomationCoverage, error) {
+	var rows []struct {
+		AutomationStatus domain.AutomationStatus
+		Count            int64
+	}
+	err := conn(ctx, r.db).Model(&domain.TestCase{}).
+		Select("automation_status, COUNT(*) AS count").
+		Where("project_id = ?", projectID).
+		Group("automation_status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &domain.AutomationCoverage{ProjectID: projectID}
+	for _, row := range rows {
+		coverage.Total += row.Count
+		switch row.AutomationStatus {
+		case domain.AutomationStatusAutomated:
+			coverage.Automated = row.Count
+		case domain.AutomationStatusToBeAutomated:
+			coverage.ToBeAutomated = row.Count
+		default:
+			coverage.Manual += row.Count
+		}
+	}
+
+	err = conn(ctx, r.db).Model(&domain.TestCase{}).
+		Where("project_id = ? AND automation_status = ?", projectID, domain.AutomationStatusAutomated).
+		Where("NOT EXISTS (SELECT 1 FROM test_case_automation_ids a WHERE a.test_case_id = test_cases.id)").
+		Count(&coverage.AutomatedWithoutIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if coverage.Total > 0 {
+		coverage.AutomatedPercent = math.Round(float64(coverage.Automated)/float64(coverage.Total)*1000) / 10
+	}
+	return coverage, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *testCaseRepository) GetStep(ctx context.Context, id uuid.UUID) (*domain.TestStep, error) {
+	var step domain.TestStep
+	err := conn(ctx, r.db).First(&step, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "test step")
+	}
+	return &step, nil
+}
+
+// AddStep inserts the step at step.Order, shifting the following steps down.
+func (r *testCaseRepository) AddStep(ctx context.Context, step *domain.TestStep) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.TestStep{}).
+			Where(`test_case_id = ? AND "order" >= ?`, step.TestCaseID, step.Order).
+			Update("order", gorm.Expr(`"order" + 1`)).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(step).Error; err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.TestCase{}, step.TestCaseID)
+	})
+}
+
+func (r *testCaseRepository) UpdateStep(ctx context.Context, step *domain.TestStep) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(step).Error; err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.TestCase{}, step.TestCaseID)
+	})
+}
+
+// DeleteStep removes the step and closes the gap it leaves in the ordering.
+func (r *testCaseRepository) DeleteStep(ctx context.Context, step *domain.TestStep) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.TestStep{}, "id = ?", step.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&domain.TestStep{}).
+			Where(`test_case_id = ? AND "order" > ?`, step.TestCaseID, step.Order).
+			Update("order", gorm.Expr(`"order" - 1`)).Error
+		if err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.TestCase{}, step.TestCaseID)
+	})
+}
+
+// MoveStep moves the step to the 1-based position order, shifting the steps
+// in between by one.
+func (r *testCaseRepository) MoveStep(ctx context.Context, step *domain.TestStep, order int) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		shift := tx.Model(&domain.TestStep{}).Where("test_case_id = ? AND id <> ?", step.TestCaseID, step.ID)
+		var err error
+		if order < step.Order {
+			err = shift.Where(`"order" >= ? AND "order" < ?`, order, step.Order).
+				Update("order", gorm.Expr(`"order" + 1`)).Error
+		} else {
+			err = shift.Where(`"order" > ? AND "order" <= ?`, step.Order, order).
+				Update("order", gorm.Expr(`"order" - 1`)).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.TestStep{}).Where("id = ?", step.ID).Update("order", order).Error
+		if err != nil {
+			return err
+		}
+		return touchVersion(tx, &domain.TestCase{}, step.TestCaseID)
+	})
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"strings"
+
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,6 +14,7 @@ type TestPlanRepository interface {
 	Create(ctx context.Context, plan *domain.TestPlan) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.TestPlan, error)
 	Update(ctx context.Context, plan *domain.TestPlan) error
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) error
 	List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestPlan, int64, error)
 	AddTestCase(ctx context.Context, planID, testCaseID uuid.UUID) error
 	AddChecklist(ctx context.Context, planID, checklistID uuid.UUID) error
@@ -25,21 +29,39 @@ func NewTestPlanRepository(db *gorm.DB) TestPlanRepository {
 }
 
 func (r *testPlanRepository) Create(ctx context.Context, plan *domain.TestPlan) error {
-	return r.db.WithContext(ctx).Create(plan).Error
+	return conn(ctx, r.db).Create(plan).Error
 }
 
 func (r *testPlanRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TestPlan, error) {
 	var plan domain.TestPlan
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Preload("Checklists").
+		Preload("Checklists.Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order(`"order" ASC`)
+		}).
 		Preload("TestCases").
 		Preload("TestCases.Steps").
+		Preload("TestCases.Attachments").
 		First(&plan, "id = ?", id).Error
-	return &plan, err
+	if err != nil {
+		return nil, dbError(err, "test plan")
+	}
+	return &plan, nil
 }
 
+// Update saves the plan if it is still at plan.Version and advances the version.
 func (r *testPlanRepository) Update(ctx context.Context, plan *domain.TestPlan) error {
-	return r.db.WithContext(ctx).Save(plan).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return saveVersioned(tx, &domain.TestPlan{}, plan.ID, &plan.Version, "test plan", func() error {
+			return tx.Save(plan).Error
+		})
+	})
+}
+
+// Delete moves the plan to the trash. Its test runs and the links to its test
+// cases and checklists stay in place, so restoring the plan brings them back.
+func (r *testPlanRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return softDelete(conn(ctx, r.db), &domain.TestPlan{}, id, deletedBy, "test plan")
 }
 
 func (r *testPlanRepository) List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestPlan, int64, error) {
@@ -48,7 +70,7 @@ func (r *testPlanRepository) List(ctx context.Context, projectID uuid.UUID, page
 
 	offset := (page - 1) * size
 
-	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	query := conn(ctx, r.db).Where("project_id = ?", projectID)
 
 	err := query.Model(&domain.TestPlan{}).Count(&total).Error
 	if err != nil {
@@ -60,15 +82,30 @@ func (r *testPlanRepository) List(ctx context.Context, projectID uuid.UUID, page
 }
 
 func (r *testPlanRepository) AddTestCase(ctx context.Context, planID, testCaseID uuid.UUID) error {
-	return r.db.WithContext(ctx).Exec(
+	err := conn(ctx, r.db).Exec(
 		"INSERT INTO test_plan_cases (test_plan_id, test_case_id) VALUES (?, ?)",
 		planID, testCaseID,
 	).Error
+	return planLinkError(err, "test case")
 }
 
 func (r *testPlanRepository) AddChecklist(ctx context.Context, planID, checklistID uuid.UUID) error {
-	return r.db.WithContext(ctx).Exec(
+	err := conn(ctx, r.db).Exec(
 		"INSERT INTO test_plan_checklists (test_plan_id, checklist_id) VALUES (?, ?)",
 		planID, checklistID,
 	).Error
+	return planLinkError(err, "checklist")
+}
+
+// planLinkError reports linking an entity twice as a conflict and linking a
+// missing entity as not found.
+func planLinkError(err error, entity string) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		code := strings.ReplaceAll(entity, " ", "_") + "_already_in_plan"
+		return domain.Conflict(code, entity+" is already in the test plan")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return domain.NotFound(entity)
+	}
+	return err
 }
@@ -18,21 +18,33 @@ func NewTestRunRepository(db *gorm.DB) TestRunRepository {
 }
 
 func (r *testRunRepository) Create(ctx context.Context, testRun *domain.TestRun) error {
-	return r.db.WithContext(ctx).Create(testRun).Error
+	return conn(ctx, r.db).Create(testRun).Error
 }
 
 func (r *testRunRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TestRun, error) {
 	var testRun domain.TestRun
-	err := r.db.WithContext(ctx).
+	err := conn(ctx, r.db).
 		Preload("Results").
-		Preload("Results.TestCase").
+		Preload("Results.TestCase", func(db *gorm.DB) *gorm.DB {
+			// Results keep showing test cases that were moved to the trash
+			return db.Unscoped()
+		}).
 		Preload("Results.ChecklistItem").
+		Preload("Results.Executor").
+		Preload("Results.Attachments").
 		First(&testRun, "id = ?", id).Error
-	return &testRun, err
+	if err != nil {
+		return nil, dbError(err, "test run")
+	}
+	return &testRun, nil
 }
 
 func (r *testRunRepository) Update(ctx context.Context, testRun *domain.TestRun) error {
-	return r.db.WithContext(ctx).Save(testRun).Error
+	return conn(ctx, r.db).Save(testRun).Error
+}
+
+func (r *testRunRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return softDelete(conn(ctx, r.db), &domain.TestRun{}, id, deletedBy, "test run")
 }
 
 func (r *testRunRepository) List(ctx context.Context, testPlanID uuid.UUID, page, size int) ([]domain.TestRun, int64, error) {
@@ -41,7 +53,7 @@ func (r *testRunRepository) List(ctx context.Context, testPlanID uuid.UUID, page
 
 	offset := (page - 1) * size
 
-	query := r.db.WithContext(ctx).Where("test_plan_id = ?", testPlanID)
+	query := conn(ctx, r.db).Where("test_plan_id = ?", testPlanID)
 
 	err := query.Model(&domain.TestRun{}).Count(&total).Error
 	if err != nil {
@@ -54,7 +66,42 @@ func (r *testRunRepository) List(ctx context.Context, testPlanID uuid.UUID, page
 
 func (r *testRunRepository) Complete(ctx context.Context, id uuid.UUID) error {
 	completedAt := time.Now()
-	return r.db.WithContext(ctx).Model(&domain.TestRun{}).
+	return conn(ctx, r.db).Model(&domain.TestRun{}).
 		Where("id = ?", id).
 		Update("completed_at", completedAt).Error
 }
+
+func (r *testRunRepository) GetResult(ctx context.Context, testRunID uuid.UUID, testCaseID, checklistItemID *uuid.UUID) (*domain.TestResult, error) {
+	var result domain.TestResult
+	query := conn(ctx, r.db).Where("test_run_id = ?", testRunID)
+
+	if testCaseID != nil {
+		query = query.Where("test_case_id = ?", *testCaseID)
+	} else {
+		query = query.Where("test_case_id IS NULL")
+	}
+	if checklistItemID != nil {
+		query = query.Where("checklist_item_id = ?", *checklistItemID)
+	} else {
+		query = query.Where("checklist_item_id IS NULL")
+	}
+
+	err := query.First(&result).Error
+	if err != nil {
+		return nil, dbError(err, "test result")
+	}
+	return &result, nil
+}
+
+func (r *testRunRepository) GetResultByID(ctx context.Context, id uuid.UUID) (*domain.TestResult, error) {
+	var result domain.TestResult
+	err := conn(ctx, r.db).First(&result, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "test result")
+	}
+	return &result, nil
+}
+
+func (r *testRunRepository) UpdateResult(ctx context.Context, result *domain.TestResult) error {
+	return conn(ctx, r.db).Save(result).Error
+}
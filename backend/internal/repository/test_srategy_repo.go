@@ -16,17 +16,28 @@ func NewTestStrategyRepository(db *gorm.DB) TestStrategyRepository {
 }
 
 func (r *testStrategyRepository) Create(ctx context.Context, strategy *domain.TestStrategy) error {
-	return r.db.WithContext(ctx).Create(strategy).Error
+	return conn(ctx, r.db).Create(strategy).Error
 }
 
 func (r *testStrategyRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.TestStrategy, error) {
 	var strategy domain.TestStrategy
-	err := r.db.WithContext(ctx).First(&strategy, "id = ?", id).Error
-	return &strategy, err
+	err := conn(ctx, r.db).First(&strategy, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "test strategy")
+	}
+	return &strategy, nil
 }
 
 func (r *testStrategyRepository) Update(ctx context.Context, strategy *domain.TestStrategy) error {
-	return r.db.WithContext(ctx).Save(strategy).Error
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return saveVersioned(tx, &domain.TestStrategy{}, strategy.ID, &strategy.Version, "test strategy", func() error {
+			return tx.Save(strategy).Error
+		})
+	})
+}
+
+func (r *testStrategyRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	return softDelete(conn(ctx, r.db), &domain.TestStrategy{}, id, deletedBy, "test strategy")
 }
 
 func (r *testStrategyRepository) List(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestStrategy, int64, error) {
@@ -35,7 +46,7 @@ func (r *testStrategyRepository) List(ctx context.Context, projectID uuid.UUID,
 
 	offset := (page - 1) * size
 
-	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	query := conn(ctx, r.db).Where("project_id = ?", projectID)
 
 	err := query.Model(&domain.TestStrategy{}).Count(&total).Error
 	if err != nil {
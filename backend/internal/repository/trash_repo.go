@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TrashRepository interface {
+	List(ctx context.Context, projectID uuid.UUID, entityType string, page, size int) ([]domain.TrashItem, int64, error)
+	Get(ctx context.Context, entityType string, id uuid.UUID) (*domain.TrashItem, error)
+	Restore(ctx context.Context, entityType string, id uuid.UUID) error
+	Purge(ctx context.Context, deletedBefore time.Time) (*domain.PurgeReport, error)
+}
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// trashTables maps the trash entity types to their tables.
+var trashTables = map[string]string{
+	domain.EntityTypeTestPlan:     "test_plans",
+	domain.EntityTypeTestCase:     "test_cases",
+	domain.EntityTypeChecklist:    "checklists",
+	domain.EntityTypeTestStrategy: "test_strategies",
+	domain.EntityTypeTestRun:      "test_runs",
+}
+
+// trashQuery selects every soft deleted entity as a domain.TrashItem row.
+// Test runs take the project of their plan, which may be deleted as well.
+const trashQuery = `
+SELECT 'test_plan' AS entity_type, id, project_id, name, deleted_at, deleted_by FROM test_plans WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'test_case', id, project_id, title, deleted_at, deleted_by FROM test_cases WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'checklist', id, project_id, name, deleted_at, deleted_by FROM checklists WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'test_strategy', id, project_id, name, deleted_at, deleted_by FROM test_strategies WHERE deleted_at IS NOT NULL
+UNION ALL
+SELECT 'test_run', r.id, p.project_id, r.name, r.deleted_at, r.deleted_by
+FROM test_runs r JOIN test_plans p ON p.id = r.test_plan_id
+WHERE r.deleted_at IS NOT NULL`
+
+func (r *trashRepository) List(ctx context.Context, projectID uuid.UUID, entityType string, page, size int) ([]domain.TrashItem, int64, error) {
+	var items []domain.TrashItem
+	var total int64
+
+	offset := (page - 1) * size
+
+	query := conn(ctx, r.db).Table("("+trashQuery+") AS trash").Where("project_id = ?", projectID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.Offset(offset).Limit(size).Order("deleted_at DESC").Scan(&items).Error
+	return items, total, err
+}
+
+func (r *trashRepository) Get(ctx context.Context, entityType string, id uuid.UUID) (*domain.TrashItem, error) {
+	if _, ok := trashTables[entityType]; !ok {
+		return nil, domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+
+	var items []domain.TrashItem
+	err := conn(ctx, r.db).Table("("+trashQuery+") AS trash").
+		Where("entity_type = ? AND id = ?", entityType, id).
+		Limit(1).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+	return &items[0], nil
+}
+
+// Restore takes the entity out of the trash. Join rows with test plans are
+// kept while an entity is in the trash, so it returns to the same plans.
+func (r *trashRepository) Restore(ctx context.Context, entityType string, id uuid.UUID) error {
+	table, ok := trashTables[entityType]
+	if !ok {
+		return domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if entityType == domain.EntityTypeTestRun {
+			var deletedPlans int64
+			err := tx.Table("test_runs r").
+				Joins("JOIN test_plans p ON p.id = r.test_plan_id").
+				Where("r.id = ? AND p.deleted_at IS NOT NULL", id).
+				Count(&deletedPlans).Error
+			if err != nil {
+				return err
+			}
+			if deletedPlans > 0 {
+				return domain.Conflict("test_plan_deleted", "the test plan of this test run is in the trash; restore it first")
+			}
+		}
+
+		result := tx.Table(table).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+		}
+		return nil
+	})
+}
+
+// Purge removes entities deleted before deletedBefore for good, together
+// with their dependent rows. Purging a test plan also purges its test runs.
+// Test cases and checklists referenced by results of remaining runs are kept.
+func (r *trashRepository) Purge(ctx context.Context, deletedBefore time.Time) (*domain.PurgeReport, error) {
+	report := &domain.PurgeReport{DeletedBefore: deletedBefore}
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var planIDs, runIDs, testCaseIDs, checklistIDs, strategyIDs []uuid.UUID
+
+		if err := tx.Table("test_plans").Where("deleted_at < ?", deletedBefore).Pluck("id", &planIDs).Error; err != nil {
+			return err
+		}
+		runs := tx.Table("test_runs").Where("deleted_at < ?", deletedBefore)
+		if len(planIDs) > 0 {
+			runs = runs.Or("test_plan_id IN ?", planIDs)
+		}
+		if err := runs.Pluck("id", &runIDs).Error; err != nil {
+			return err
+		}
+
+		if len(runIDs) > 0 {
+			if err := r.purgeAttachments(tx, report, "test_result_id IN (SELECT id FROM test_results WHERE test_run_id IN ?)", runIDs); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM test_results WHERE test_run_id IN ?", runIDs).Error; err != nil {
+				return err
+			}
+			if err := purgeRows(tx, "test_runs", runIDs, &report.TestRuns); err != nil {
+				return err
+			}
+		}
+
+		if len(planIDs) > 0 {
+			for _, join := range []string{"test_plan_cases", "test_plan_checklists"} {
+				if err := tx.Exec("DELETE FROM "+join+" WHERE test_plan_id IN ?", planIDs).Error; err != nil {
+					return err
+				}
+			}
+			if err := purgeRows(tx, "test_plans", planIDs, &report.TestPlans); err != nil {
+				return err
+			}
+		}
+
+		// Test cases and checklists still executed in a remaining run stay
+		var kept int64
+		if err := tx.Table("test_cases").
+			Where("deleted_at < ?", deletedBefore).
+			Where("EXISTS (SELECT 1 FROM test_results WHERE test_results.test_case_id = test_cases.id)").
+			Count(&kept).Error; err != nil {
+			return err
+		}
+		report.Kept += kept
+		if err := tx.Table("test_cases").
+			Where("deleted_at < ?", deletedBefore).
+			Where("NOT EXISTS (SELECT 1 FROM test_results WHERE test_results.test_case_id = test_cases.id)").
+			Pluck("id", &testCaseIDs).Error; err != nil {
+			return err
+		}
+
+		if len(testCaseIDs) > 0 {
+			if err := r.purgeAttachments(tx, report, "test_case_id IN ?", testCaseIDs); err != nil {
+				return err
+			}
+			for _, table := range []string{"test_plan_cases", "test_steps", "test_case_automation_ids"} {
+				if err := tx.Exec("DELETE FROM "+table+" WHERE test_case_id IN ?", testCaseIDs).Error; err != nil {
+					return err
+				}
+			}
+			if err := purgeRows(tx, "test_cases", testCaseIDs, &report.TestCases); err != nil {
+				return err
+			}
+		}
+
+		const checklistInUse = `EXISTS (SELECT 1 FROM test_results JOIN checklist_items ON checklist_items.id = test_results.checklist_item_id
+			WHERE checklist_items.checklist_id = checklists.id)`
+		if err := tx.Table("checklists").Where("deleted_at < ?", deletedBefore).Where(checklistInUse).Count(&kept).Error; err != nil {
+			return err
+		}
+		report.Kept += kept
+		if err := tx.Table("checklists").Where("deleted_at < ?", deletedBefore).Where("NOT "+checklistInUse).Pluck("id", &checklistIDs).Error; err != nil {
+			return err
+		}
+
+		if len(checklistIDs) > 0 {
+			for _, table := range []string{"test_plan_checklists", "checklist_items"} {
+				if err := tx.Exec("DELETE FROM "+table+" WHERE checklist_id IN ?", checklistIDs).Error; err != nil {
+					return err
+				}
+			}
+			if err := purgeRows(tx, "checklists", checklistIDs, &report.Checklists); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Table("test_strategies").Where("deleted_at < ?", deletedBefore).Pluck("id", &strategyIDs).Error; err != nil {
+			return err
+		}
+		if len(strategyIDs) > 0 {
+			if err := purgeRows(tx, "test_strategies", strategyIDs, &report.TestStrategies); err != nil {
+				return err
+			}
+		}
+
+		// Comments go with their entity; history is kept as the audit trail
+		purged := map[string][]uuid.UUID{
+			domain.EntityTypeTestPlan:     planIDs,
+			domain.EntityTypeTestRun:      runIDs,
+			domain.EntityTypeTestCase:     testCaseIDs,
+			domain.EntityTypeChecklist:    checklistIDs,
+			domain.EntityTypeTestStrategy: strategyIDs,
+		}
+		for entityType, ids := range purged {
+			if len(ids) == 0 {
+				continue
+			}
+			if err := tx.Exec("DELETE FROM comments WHERE entity_type = ? AND entity_id IN ?", entityType, ids).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// purgeAttachments deletes the attachment rows matching the condition and
+// collects their files for removal after the transaction.
+func (r *trashRepository) purgeAttachments(tx *gorm.DB, report *domain.PurgeReport, condition string, args ...interface{}) error {
+	var paths []string
+	if err := tx.Model(&domain.Attachment{}).Where(condition, args...).Pluck("file_path", &paths).Error; err != nil {
+		return err
+	}
+	if err := tx.Where(condition, args...).Delete(&domain.Attachment{}).Error; err != nil {
+		return err
+	}
+	report.FilePaths = append(report.FilePaths, paths...)
+	return nil
+}
+
+func purgeRows(tx *gorm.DB, table string, ids []uuid.UUID, count *int64) error {
+	result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id IN ?", table), ids)
+	*count = result.RowsAffected
+	return result.Error
+}
+
+// softDelete moves a row into the trash. Rows already in the trash are not
+// found, since GORM scopes updates of soft deletable models to live rows.
+func softDelete(db *gorm.DB, model interface{}, id, deletedBy uuid.UUID, entity string) error {
+	var by *uuid.UUID
+	if deletedBy != uuid.Nil {
+		by = &deletedBy
+	}
+
+	result := db.Model(model).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": by,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.NotFound(entity)
+	}
+	return nil
+}
@@ -24,27 +24,33 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return dbError(conn(ctx, r.db).Create(user).Error, "user")
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).First(&user, "id = ?", id).Error
-	return &user, err
+	err := conn(ctx, r.db).First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+	return &user, nil
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error
-	return &user, err
+	err := conn(ctx, r.db).First(&user, "email = ?", email).Error
+	if err != nil {
+		return nil, dbError(err, "user")
+	}
+	return &user, nil
 }
 
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return conn(ctx, r.db).Save(user).Error
 }
 
 func (r *userRepository) List(ctx context.Context) ([]domain.User, error) {
 	var users []domain.User
-	err := r.db.WithContext(ctx).Find(&users).Error
+	err := conn(ctx, r.db).Find(&users).Error
 	return users, err
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserTokenRepository interface {
+	// Create stores the token and deletes the user's unused tokens for the same
+	// purpose, so only the most recently mailed link works.
+	Create(ctx context.Context, token *domain.UserToken) error
+	// Consume marks an unused, unexpired token as used and returns it. Unknown,
+	// used and expired tokens are all reported as NotFound.
+	Consume(ctx context.Context, hash string, purpose domain.UserTokenPurpose) (*domain.UserToken, error)
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Delete(&domain.UserToken{}).Error
+		if err != nil {
+			return err
+		}
+
+		return dbError(tx.Create(token).Error, "token")
+	})
+}
+
+func (r *userTokenRepository) Consume(ctx context.Context, hash string, purpose domain.UserTokenPurpose) (*domain.UserToken, error) {
+	var token domain.UserToken
+	now := time.Now()
+
+	// Checking and marking in one statement keeps a token from being used twice concurrently
+	result := conn(ctx, r.db).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.NotFound("token")
+	}
+
+	return &token, nil
+}
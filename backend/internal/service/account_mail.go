@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+)
+
+// accountLink builds the frontend URL a mailed token is opened with.
+func accountLink(appURL, path, token string) string {
+	return strings.TrimRight(appURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func verificationMail(link string) (string, string) {
+	return "Verify your email address", fmt.Sprintf(`Welcome to Test Management System.
+
+Open the link below to verify your email address and activate your account:
+
+%s
+
+The link is valid for 24 hours. If you did not sign up, you can ignore this email.
+`, link)
+}
+
+func passwordResetMail(link string) (string, string) {
+	return "Reset your password", fmt.Sprintf(`A password reset was requested for your Test Management System account.
+
+Open the link below to choose a new password:
+
+%s
+
+The link is valid for one hour and can be used once. If you did not ask for a
+reset, you can ignore this email and your password stays unchanged.
+`, link)
+}
+
+func passwordChangedMail() (string, string) {
+	return "Your password was changed", `The password of your Test Management System account was just changed and
+you were signed out on all devices.
+
+If you did not do this, reset your password right away and contact an administrator.
+`
+}
+
+func invitationMail(link string, role domain.UserRole) (string, string) {
+	return "You are invited to Test Management System", fmt.Sprintf(`You have been invited to Test Management System as a %s.
+
+Open the link below to set your password and activate your account:
+
+%s
+
+The invitation is valid for 7 days.
+`, role, link)
+}
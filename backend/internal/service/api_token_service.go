@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultTokenLifetime applies when a token is created without an expiry
+	defaultTokenLifetime = 90 * 24 * time.Hour
+	// lastUsedInterval limits how often last_used_at is written for a busy token
+	lastUsedInterval = time.Minute
+)
+
+type apiTokenService struct {
+	repo     repository.APITokenRepository
+	userRepo repository.UserRepository
+}
+
+func NewAPITokenService(repo repository.APITokenRepository, userRepo repository.UserRepository) APITokenService {
+	return &apiTokenService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *apiTokenService) CreateToken(ctx context.Context, token *domain.APIToken, lifetime time.Duration) (string, error) {
+	if len(token.Scopes) == 0 {
+		return "", domain.Validation("invalid_scopes", "at least one scope is required",
+			domain.FieldError{Field: "scopes", Message: "must not be empty"})
+	}
+	for _, scope := range token.Scopes {
+		if !domain.ValidTokenScope(scope) {
+			return "", domain.Validation("invalid_scopes", "invalid token scope",
+				domain.FieldError{Field: "scopes", Message: "must be read, write-results or admin"})
+		}
+	}
+
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	plain := domain.APITokenPrefix + secret
+
+	now := time.Now()
+	token.ID = uuid.New()
+	token.Prefix = plain[:len(domain.APITokenPrefix)+6]
+	token.TokenHash = hashToken(plain)
+	token.ExpiresAt = now.Add(lifetime)
+	token.LastUsedAt = nil
+	token.RevokedAt = nil
+	token.CreatedAt = now
+
+	if err := s.repo.Create(ctx, token); err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+func (s *apiTokenService) GetToken(ctx context.Context, id uuid.UUID) (*domain.APIToken, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *apiTokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *apiTokenService) RevokeToken(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *apiTokenService) Authenticate(ctx context.Context, plain string) (*domain.User, *domain.APIToken, error) {
+	token, err := s.repo.GetByHash(ctx, hashToken(plain))
+	if domain.IsNotFound(err) {
+		return nil, nil, errInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, nil, errInvalidToken
+	}
+
+	// The user is loaded on every request so role changes apply at once
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if domain.IsNotFound(err) {
+		return nil, nil, errInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval {
+		if err := s.repo.TouchLastUsed(ctx, token.ID, now); err != nil {
+			return nil, nil, err
+		}
+		token.LastUsedAt = &now
+	}
+
+	return user, token, nil
+}
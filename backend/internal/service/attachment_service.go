@@ -0,0 +1,179 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/AntVerkh/test-management-system/pkg/storage"
+	"github.com/google/uuid"
+)
+
+type attachmentService struct {
+	repo             repository.AttachmentRepository
+	testCaseRepo     repository.TestCaseRepository
+	testRunRepo      repository.TestRunRepository
+	storage          storage.FileStorage
+	maxSize          int64
+	allowedMimeTypes []string
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	testCaseRepo repository.TestCaseRepository,
+	testRunRepo repository.TestRunRepository,
+	fileStorage storage.FileStorage,
+	maxSize int64,
+	allowedMimeTypes []string,
+) AttachmentService {
+	return &attachmentService{
+		repo:             repo,
+		testCaseRepo:     testCaseRepo,
+		testRunRepo:      testRunRepo,
+		storage:          fileStorage,
+		maxSize:          maxSize,
+		allowedMimeTypes: allowedMimeTypes,
+	}
+}
+
+// Upload stores the file and records it against exactly one of the attachment's
+// test case or test result. The MIME type is sniffed from the content and only
+// falls back to the declared type when sniffing is inconclusive.
+func (s *attachmentService) Upload(ctx context.Context, attachment *domain.Attachment, file io.Reader) error {
+	if (attachment.TestCaseID == nil) == (attachment.TestResultID == nil) {
+		return domain.Validation("invalid_attachment_target", "attachment must belong to either a test case or a test result")
+	}
+	if attachment.TestCaseID != nil {
+		if _, err := s.testCaseRepo.GetByID(ctx, *attachment.TestCaseID); err != nil {
+			return err
+		}
+	}
+	if attachment.TestResultID != nil {
+		if _, err := s.testRunRepo.GetResultByID(ctx, *attachment.TestResultID); err != nil {
+			return err
+		}
+	}
+
+	if attachment.FileSize > s.maxSize {
+		return s.errFileTooLarge()
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	head = head[:n]
+
+	attachment.MimeType = s.detectMimeType(head, attachment.MimeType)
+	if !s.isAllowed(attachment.MimeType) {
+		return domain.Validation("file_type_not_allowed", fmt.Sprintf("file type %s is not allowed", attachment.MimeType),
+			domain.FieldError{Field: "file", Message: "type is not allowed"})
+	}
+
+	// Read one byte past the limit so oversized streams are detected even when
+	// the declared size was wrong
+	counter := &countingReader{reader: io.LimitReader(io.MultiReader(bytes.NewReader(head), file), s.maxSize+1)}
+	path, err := s.storage.Save(counter, attachment.FileName)
+	if err != nil {
+		return err
+	}
+	if counter.count > s.maxSize {
+		_ = s.storage.Delete(path)
+		return s.errFileTooLarge()
+	}
+
+	attachment.ID = uuid.New()
+	attachment.FilePath = path
+	attachment.FileSize = counter.count
+	attachment.UploadedAt = time.Now()
+
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		_ = s.storage.Delete(path)
+		return err
+	}
+
+	return nil
+}
+
+func (s *attachmentService) GetAttachment(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *attachmentService) Open(ctx context.Context, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.storage.Get(attachment.FilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, file, nil
+}
+
+// DeleteAttachment removes the record first so a failed file removal never
+// leaves a record pointing at a missing file.
+func (s *attachmentService) DeleteAttachment(ctx context.Context, id uuid.UUID) error {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.storage.Delete(attachment.FilePath)
+}
+
+func (s *attachmentService) errFileTooLarge() error {
+	return domain.Validation("file_too_large", fmt.Sprintf("file exceeds the maximum size of %d bytes", s.maxSize),
+		domain.FieldError{Field: "file", Message: fmt.Sprintf("must be at most %d bytes", s.maxSize)})
+}
+
+func (s *attachmentService) detectMimeType(head []byte, declared string) string {
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if detected != "application/octet-stream" && detected != "text/plain" {
+		return detected
+	}
+
+	if declared != "" {
+		if mediaType, _, err := mime.ParseMediaType(declared); err == nil {
+			return mediaType
+		}
+	}
+	return detected
+}
+
+func (s *attachmentService) isAllowed(mimeType string) bool {
+	for _, allowed := range s.allowedMimeTypes {
+		if allowed == mimeType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
-	"errors"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
 	"time"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
@@ -12,54 +16,524 @@ import (
 )
 
 type authService struct {
-	userRepo   repository.UserRepository
-	jwtService JWTService
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	userTokenRepo   repository.UserTokenRepository
+	oidcRequestRepo repository.OIDCAuthRequestRepository
+	jwtService      JWTService
+	mfaService      MFAService
+	mailer          Mailer
+	refreshTTL      time.Duration
+	appURL          string
+	sso             SSOConfig
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtService JWTService) AuthService {
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+var (
+	errInvalidCredentials  = domain.Unauthorized("invalid_credentials", "invalid credentials")
+	errInvalidToken        = domain.Unauthorized("invalid_token", "invalid token")
+	errInvalidRefreshToken = domain.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+	errEmailNotVerified    = domain.Forbidden("email_not_verified", "email address has not been verified")
+	errInvalidMFAToken     = domain.Unauthorized("invalid_mfa_token", "invalid or expired MFA token, log in again")
+	errInvalidUserToken    = domain.Validation("invalid_token", "the link is invalid, expired or already used",
+		domain.FieldError{Field: "token", Message: "is invalid, expired or already used"})
+)
+
+// NewAuthService creates the service. refreshTTL is how long a session stays
+// usable without being refreshed, appURL is the frontend address that mailed
+// links point to and sso configures single sign-on, if any.
+func NewAuthService(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	userTokenRepo repository.UserTokenRepository,
+	oidcRequestRepo repository.OIDCAuthRequestRepository,
+	jwtService JWTService,
+	mfaService MFAService,
+	mailer Mailer,
+	refreshTTL time.Duration,
+	appURL string,
+	sso SSOConfig,
+) AuthService {
 	return &authService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		userTokenRepo:   userTokenRepo,
+		oidcRequestRepo: oidcRequestRepo,
+		jwtService:      jwtService,
+		mfaService:      mfaService,
+		mailer:          mailer,
+		refreshTTL:      refreshTTL,
+		appURL:          appURL,
+		sso:             sso,
 	}
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, *domain.User, error) {
+func (s *authService) Login(ctx context.Context, email, password string) (*domain.LoginResult, error) {
 	user, err := s.userRepo.GetByEmail(ctx, email)
+	if domain.IsNotFound(err) {
+		return nil, errInvalidCredentials
+	}
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return nil, err
+	}
+
+	if user.IsServiceAccount {
+		return nil, errInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return nil, errInvalidCredentials
 	}
 
-	token, err := s.jwtService.GenerateToken(user)
+	if user.EmailVerifiedAt == nil {
+		return nil, errEmailNotVerified
+	}
+
+	return s.authenticated(ctx, user)
+}
+
+func (s *authService) CompleteMFALogin(ctx context.Context, mfaToken, code string) (*domain.LoginResult, error) {
+	user, err := s.challengeUser(ctx, mfaToken)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	return token, user, nil
+	if err := s.mfaService.Verify(ctx, user.ID, code); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+func (s *authService) EnrollMFALogin(ctx context.Context, mfaToken string) (*domain.MFASetup, error) {
+	user, err := s.challengeUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mfaService.Enroll(ctx, user.ID)
+}
+
+func (s *authService) ConfirmMFALogin(ctx context.Context, mfaToken, code string) (*domain.LoginResult, error) {
+	user, err := s.challengeUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.mfaService.Confirm(ctx, user.ID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	result.RecoveryCodes = recoveryCodes
+	return result, nil
 }
 
 func (s *authService) Register(ctx context.Context, user *domain.User) error {
-	existing, _ := s.userRepo.GetByEmail(ctx, user.Email)
-	if existing != nil {
-		return errors.New("user already exists")
+	_, err := s.userRepo.GetByEmail(ctx, user.Email)
+	if err == nil {
+		return domain.Conflict("user_exists", "user already exists")
+	}
+	if !domain.IsNotFound(err) {
+		return err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 
 	user.ID = uuid.New()
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
+	user.EmailVerifiedAt = nil
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	return s.userRepo.Create(ctx, user)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	// The account exists either way; a lost mail can be sent again with ResendVerification
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("register: sending verification to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	userToken, err := s.userTokenRepo.Consume(ctx, hashToken(token), domain.UserTokenEmailVerification)
+	if domain.IsNotFound(err) {
+		return errInvalidUserToken
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.markVerified(ctx, user)
+}
+
+func (s *authService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if domain.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil || user.IsServiceAccount {
+		return nil
+	}
+
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("resend verification to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) (*domain.AuthTokens, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return nil, domain.Validation("invalid_password", "current password is incorrect",
+			domain.FieldError{Field: "current_password", Message: "is incorrect"})
+	}
+
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
+		return nil, err
+	}
+
+	// Every other session was just revoked, so the caller gets a fresh one
+	return s.startSession(ctx, user)
+}
+
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if domain.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.IsServiceAccount {
+		return nil
+	}
+
+	token, err := s.issueUserToken(ctx, user, domain.UserTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	subject, body := passwordResetMail(accountLink(s.appURL, "/reset-password", token))
+	if err := s.mailer.Send(ctx, user.Email, subject, body); err != nil {
+		log.Printf("password reset mail to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	userToken, err := s.userTokenRepo.Consume(ctx, hashToken(token), domain.UserTokenPasswordReset)
+	if domain.IsNotFound(err) {
+		return errInvalidUserToken
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Following the mailed link proves the address too
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*domain.AuthTokens, error) {
+	hash := hashToken(refreshToken)
+	session, err := s.sessionRepo.GetByTokenHash(ctx, hash)
+	if domain.IsNotFound(err) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A refresh token that was already rotated away is being replayed, so it
+	// may have leaked: end the session rather than trust either holder.
+	if session.RefreshTokenHash != hash {
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidRefreshToken
+	}
+
+	if !session.Active(time.Now()) {
+		return nil, errInvalidRefreshToken
+	}
+
+	// Reload the user so the new access token carries the current role
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if domain.IsNotFound(err) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessionRepo.Rotate(ctx, session.ID, hash, newHash, time.Now().Add(s.refreshTTL))
+	if domain.IsNotFound(err) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID, token)
+}
+
+func (s *authService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	return s.sessionRepo.Revoke(ctx, sessionID)
+}
+
+func (s *authService) ValidateToken(ctx context.Context, token string) (*domain.User, uuid.UUID, error) {
+	user, sessionID, err := s.jwtService.ValidateToken(token)
+	if err != nil {
+		return nil, uuid.Nil, errInvalidToken
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if domain.IsNotFound(err) {
+		return nil, uuid.Nil, errInvalidToken
+	}
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	if session.UserID != user.ID || !session.Active(time.Now()) {
+		return nil, uuid.Nil, errInvalidToken
+	}
+
+	return user, sessionID, nil
+}
+
+// authenticated continues a login once the user proved who they are: it
+// starts the session, or asks for a second factor the user has or needs.
+func (s *authService) authenticated(ctx context.Context, user *domain.User) (*domain.LoginResult, error) {
+	mfa, err := s.mfaService.Status(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first factor alone only earns a challenge token when there is or has to be a second one
+	if mfa.Enabled || mfa.Required {
+		mfaToken, err := s.jwtService.GenerateChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.LoginResult{
+			MFARequired:           true,
+			MFAToken:              mfaToken,
+			MFAEnrollmentRequired: !mfa.Enabled,
+		}, nil
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// challengeUser returns the user an MFA token was issued for.
+func (s *authService) challengeUser(ctx context.Context, mfaToken string) (*domain.User, error) {
+	userID, err := s.jwtService.ValidateChallenge(mfaToken)
+	if err != nil {
+		return nil, errInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if domain.IsNotFound(err) {
+		return nil, errInvalidMFAToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *authService) completeLogin(ctx context.Context, user *domain.User) (*domain.LoginResult, error) {
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResult{AuthTokens: tokens, User: user}, nil
+}
+
+// setPassword stores a new password and signs the user out everywhere.
+func (s *authService) setPassword(ctx context.Context, user *domain.User, password string) error {
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	subject, body := passwordChangedMail()
+	if err := s.mailer.Send(ctx, user.Email, subject, body); err != nil {
+		log.Printf("password changed mail to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+func (s *authService) markVerified(ctx context.Context, user *domain.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	user.UpdatedAt = now
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *authService) sendVerification(ctx context.Context, user *domain.User) error {
+	token, err := s.issueUserToken(ctx, user, domain.UserTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	subject, body := verificationMail(accountLink(s.appURL, "/verify-email", token))
+	return s.mailer.Send(ctx, user.Email, subject, body)
+}
+
+// issueUserToken stores a single-use token for the user and returns its plain value.
+func (s *authService) issueUserToken(ctx context.Context, user *domain.User, purpose domain.UserTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	userToken := &domain.UserToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.userTokenRepo.Create(ctx, userToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// startSession records a new session for the user and issues its first token pair.
+func (s *authService) startSession(ctx context.Context, user *domain.User) (*domain.AuthTokens, error) {
+	now := time.Now()
+
+	// Sessions that can no longer be used are only kept until the user next signs in
+	if err := s.sessionRepo.DeleteInactive(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &domain.Session{
+		ID:               uuid.New(),
+		UserID:           user.ID,
+		RefreshTokenHash: hash,
+		ExpiresAt:        now.Add(s.refreshTTL),
+		LastUsedAt:       now,
+		CreatedAt:        now,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID, token)
+}
+
+func (s *authService) issueTokens(user *domain.User, sessionID uuid.UUID, refreshToken string) (*domain.AuthTokens, error) {
+	accessToken, expiresAt, err := s.jwtService.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthTokens{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
+
+// newRefreshToken returns a random refresh token and the hash stored for it.
+func newRefreshToken() (string, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return token, hashToken(token), nil
+}
+
+// randomToken returns 32 random bytes encoded to be safe in URLs and headers.
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
 }
 
-func (s *authService) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
-	return s.jwtService.ValidateToken(token)
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
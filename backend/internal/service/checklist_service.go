@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type checklistService struct {
+	repo    repository.ChecklistRepository
+	history HistoryService
+	tx      repository.Transactor
+}
+
+var errIncompleteItemOrder = domain.Validation("invalid_item_order", "item list must contain every checklist item exactly once",
+	domain.FieldError{Field: "item_ids", Message: "must contain every checklist item exactly once"})
+
+func NewChecklistService(repo repository.ChecklistRepository, history HistoryService, tx repository.Transactor) ChecklistService {
+	return &checklistService{
+		repo:    repo,
+		history: history,
+		tx:      tx,
+	}
+}
+
+func (s *checklistService) CreateChecklist(ctx context.Context, checklist *domain.Checklist) error {
+	checklist.ID = uuid.New()
+	checklist.CreatedAt = time.Now()
+	checklist.UpdatedAt = time.Now()
+
+	// Generate IDs for items and keep their order contiguous
+	for i := range checklist.Items {
+		checklist.Items[i].ID = uuid.New()
+		checklist.Items[i].ChecklistID = checklist.ID
+		checklist.Items[i].Order = i + 1
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, checklist); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeChecklist, checklist.ID, domain.HistoryActionCreated, nil, checklist)
+	})
+}
+
+func (s *checklistService) GetChecklist(ctx context.Context, id uuid.UUID) (*domain.Checklist, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *checklistService) UpdateChecklist(ctx context.Context, checklist *domain.Checklist) error {
+	before, err := s.repo.GetByID(ctx, checklist.ID)
+	if err != nil {
+		return err
+	}
+	if before.Version != checklist.Version {
+		return domain.VersionConflict("checklist", before)
+	}
+
+	checklist.UpdatedAt = time.Now()
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, checklist); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeChecklist, checklist.ID, domain.HistoryActionUpdated, before, checklist)
+	})
+	return withCurrent(err, "checklist", func() (interface{}, error) {
+		return s.repo.GetByID(ctx, checklist.ID)
+	})
+}
+
+// DeleteChecklist moves the checklist to the trash.
+func (s *checklistService) DeleteChecklist(ctx context.Context, id uuid.UUID) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, domain.ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeChecklist, id, domain.HistoryActionDeleted, nil)
+	})
+}
+
+func (s *checklistService) ListChecklists(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.Checklist, int64, error) {
+	return s.repo.List(ctx, projectID, page, size)
+}
+
+// AddItem inserts the item at the requested 1-based position, or appends it
+// when the position is missing or out of range.
+func (s *checklistService) AddItem(ctx context.Context, item *domain.ChecklistItem) error {
+	checklist, err := s.repo.GetByID(ctx, item.ChecklistID)
+	if err != nil {
+		return err
+	}
+
+	if item.Order < 1 || item.Order > len(checklist.Items)+1 {
+		item.Order = len(checklist.Items) + 1
+	}
+	item.ID = uuid.New()
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddItem(ctx, item); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeChecklist, item.ChecklistID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"items": {New: item},
+		})
+	})
+}
+
+func (s *checklistService) UpdateItem(ctx context.Context, item *domain.ChecklistItem) error {
+	existing, err := s.repo.GetItem(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	if existing.ChecklistID != item.ChecklistID {
+		return domain.NotFound("checklist item")
+	}
+
+	// Position changes go through ReorderItems
+	item.Order = existing.Order
+
+	changes, err := domain.Diff(existing, item)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateItem(ctx, item); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeChecklist, item.ChecklistID, domain.HistoryActionUpdated,
+			domain.NestChanges("items."+item.ID.String(), changes))
+	})
+}
+
+func (s *checklistService) RemoveItem(ctx context.Context, checklistID, itemID uuid.UUID) error {
+	item, err := s.repo.GetItem(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.ChecklistID != checklistID {
+		return domain.NotFound("checklist item")
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteItem(ctx, item); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeChecklist, checklistID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"items": {Old: item},
+		})
+	})
+}
+
+// ReorderItems expects every item of the checklist exactly once, in the new order.
+func (s *checklistService) ReorderItems(ctx context.Context, checklistID uuid.UUID, itemIDs []uuid.UUID) error {
+	checklist, err := s.repo.GetByID(ctx, checklistID)
+	if err != nil {
+		return err
+	}
+
+	if len(itemIDs) != len(checklist.Items) {
+		return errIncompleteItemOrder
+	}
+
+	existing := make(map[uuid.UUID]bool, len(checklist.Items))
+	previousOrder := make([]uuid.UUID, 0, len(checklist.Items))
+	for _, item := range checklist.Items {
+		existing[item.ID] = true
+		previousOrder = append(previousOrder, item.ID)
+	}
+	for _, itemID := range itemIDs {
+		if !existing[itemID] {
+			return errIncompleteItemOrder
+		}
+		delete(existing, itemID)
+	}
+
+	changes, err := domain.Diff(
+		map[string]interface{}{"item_order": previousOrder},
+		map[string]interface{}{"item_order": itemIDs},
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ReorderItems(ctx, checklistID, itemIDs); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeChecklist, checklistID, domain.HistoryActionUpdated, changes)
+	})
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type commentService struct {
+	repo repository.CommentRepository
+}
+
+var errUnsupportedEntityType = domain.Validation("unsupported_entity_type", "unsupported entity type",
+	domain.FieldError{Field: "entity_type", Message: "must be a project-scoped entity type"})
+
+func NewCommentService(repo repository.CommentRepository) CommentService {
+	return &commentService{repo: repo}
+}
+
+// CreateComment starts a new thread, or adds a reply when ParentID is set.
+// Replies to replies are attached to the root of the thread.
+func (s *commentService) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	if !domain.ValidEntityType(comment.EntityType) {
+		return errUnsupportedEntityType
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.EntityType != comment.EntityType || parent.EntityID != comment.EntityID {
+			return domain.Validation("invalid_parent_comment", "parent comment belongs to another entity",
+				domain.FieldError{Field: "parent_id", Message: "belongs to another entity"})
+		}
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	comment.ID = uuid.New()
+	comment.Resolved = false
+	comment.ResolvedBy = nil
+	comment.ResolvedAt = nil
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = time.Now()
+
+	return s.repo.Create(ctx, comment)
+}
+
+func (s *commentService) GetComment(ctx context.Context, id uuid.UUID) (*domain.Comment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, comment *domain.Comment) error {
+	comment.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, comment)
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *commentService) ListComments(ctx context.Context, entityType string, entityID uuid.UUID, resolved *bool, page, size int) ([]domain.Comment, int64, error) {
+	if !domain.ValidEntityType(entityType) {
+		return nil, 0, errUnsupportedEntityType
+	}
+	return s.repo.List(ctx, entityType, entityID, resolved, page, size)
+}
+
+// SetResolved marks a thread as resolved or reopens it. Only top-level
+// comments carry a resolution state.
+func (s *commentService) SetResolved(ctx context.Context, id, userID uuid.UUID, resolved bool) (*domain.Comment, error) {
+	comment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.ParentID != nil {
+		return nil, domain.Conflict("comment_is_reply", "only top-level comments can be resolved")
+	}
+
+	comment.Resolved = resolved
+	if resolved {
+		now := time.Now()
+		comment.ResolvedBy = &userID
+		comment.ResolvedAt = &now
+	} else {
+		comment.ResolvedBy = nil
+		comment.ResolvedAt = nil
+	}
+
+	if err := s.UpdateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
@@ -2,8 +2,8 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
@@ -17,7 +17,9 @@ type exportService struct {
 	checklistRepo    repository.ChecklistRepository
 	testStrategyRepo repository.TestStrategyRepository
 	testRunRepo      repository.TestRunRepository
-	exporter         domain.Exporter
+	historyRepo      repository.HistoryRepository
+	commentRepo      repository.CommentRepository
+	exporters        *domain.ExporterRegistry
 }
 
 func NewExportService(
@@ -26,7 +28,9 @@ func NewExportService(
 	checklistRepo repository.ChecklistRepository,
 	testStrategyRepo repository.TestStrategyRepository,
 	testRunRepo repository.TestRunRepository,
-	exporter domain.Exporter,
+	historyRepo repository.HistoryRepository,
+	commentRepo repository.CommentRepository,
+	exporters *domain.ExporterRegistry,
 ) ExportService {
 	return &exportService{
 		testPlanRepo:     testPlanRepo,
@@ -34,14 +38,16 @@ func NewExportService(
 		checklistRepo:    checklistRepo,
 		testStrategyRepo: testStrategyRepo,
 		testRunRepo:      testRunRepo,
-		exporter:         exporter,
+		historyRepo:      historyRepo,
+		commentRepo:      commentRepo,
+		exporters:        exporters,
 	}
 }
 
 func (s *exportService) ExportEntity(ctx context.Context, req *domain.ExportRequest) (string, string, error) {
 	entityID, err := uuid.Parse(req.EntityID)
 	if err != nil {
-		return "", "", errors.New("invalid entity ID")
+		return "", "", domain.Validation("invalid_id", "invalid entity ID", domain.FieldError{Field: "entity_id", Message: "must be a UUID"})
 	}
 
 	switch req.EntityType {
@@ -56,126 +62,220 @@ func (s *exportService) ExportEntity(ctx context.Context, req *domain.ExportRequ
 	case "test_run":
 		return s.ExportTestRun(ctx, entityID, req.Format, req.IncludeHistory, req.IncludeComments)
 	default:
-		return "", "", errors.New("unsupported entity type")
+		return "", "", errUnsupportedEntityType
 	}
 }
 
 func (s *exportService) ExportTestPlan(ctx context.Context, planID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error) {
-	plan, err := s.testPlanRepo.GetByID(ctx, planID)
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeTestPlan)
 	if err != nil {
-		return "", "", errors.New("test plan not found")
+		return "", "", err
 	}
 
-	var content string
-	var filename string
+	plan, err := s.testPlanRepo.GetByID(ctx, planID)
+	if err != nil {
+		return "", "", err
+	}
 
-	switch format {
-	case domain.ExportFormatMarkdown:
-		content, err = s.exporter.ExportTestPlan(plan, includeHistory, includeComments)
-		filename = fmt.Sprintf("test_plan_%s_%s.md", plan.Name, time.Now().Format("20060102_150405"))
-	default:
-		return "", "", errors.New("unsupported export format")
+	plan.History, plan.Comments, err = s.loadActivity(ctx, domain.EntityTypeTestPlan, plan.ID, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
 	}
 
+	content, err := exporter.ExportTestPlan(plan, includeHistory, includeComments)
 	if err != nil {
 		return "", "", err
 	}
 
-	return content, filename, nil
+	return content, exportFilename("test_plan", plan.Name, info), nil
 }
 
 func (s *exportService) ExportTestCase(ctx context.Context, testCaseID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error) {
-	testCase, err := s.testCaseRepo.GetByID(ctx, testCaseID)
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeTestCase)
 	if err != nil {
-		return "", "", errors.New("test case not found")
+		return "", "", err
 	}
 
-	var content string
-	var filename string
+	testCase, err := s.testCaseRepo.GetByID(ctx, testCaseID)
+	if err != nil {
+		return "", "", err
+	}
 
-	switch format {
-	case domain.ExportFormatMarkdown:
-		content, err = s.exporter.ExportTestCase(testCase, includeHistory, includeComments)
-		filename = fmt.Sprintf("test_case_%s_%s.md", testCase.Title, time.Now().Format("20060102_150405"))
-	default:
-		return "", "", errors.New("unsupported export format")
+	testCase.History, testCase.Comments, err = s.loadActivity(ctx, domain.EntityTypeTestCase, testCase.ID, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
 	}
 
+	content, err := exporter.ExportTestCase(testCase, includeHistory, includeComments)
 	if err != nil {
 		return "", "", err
 	}
 
-	return content, filename, nil
+	return content, exportFilename("test_case", testCase.Title, info), nil
 }
 
 func (s *exportService) ExportChecklist(ctx context.Context, checklistID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error) {
-	checklist, err := s.checklistRepo.GetByID(ctx, checklistID)
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeChecklist)
 	if err != nil {
-		return "", "", errors.New("checklist not found")
+		return "", "", err
 	}
 
-	var content string
-	var filename string
+	checklist, err := s.checklistRepo.GetByID(ctx, checklistID)
+	if err != nil {
+		return "", "", err
+	}
 
-	switch format {
-	case domain.ExportFormatMarkdown:
-		content, err = s.exporter.ExportChecklist(checklist, includeHistory, includeComments)
-		filename = fmt.Sprintf("checklist_%s_%s.md", checklist.Name, time.Now().Format("20060102_150405"))
-	default:
-		return "", "", errors.New("unsupported export format")
+	checklist.History, checklist.Comments, err = s.loadActivity(ctx, domain.EntityTypeChecklist, checklist.ID, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
 	}
 
+	content, err := exporter.ExportChecklist(checklist, includeHistory, includeComments)
 	if err != nil {
 		return "", "", err
 	}
 
-	return content, filename, nil
+	return content, exportFilename("checklist", checklist.Name, info), nil
 }
 
 func (s *exportService) ExportTestStrategy(ctx context.Context, strategyID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error) {
-	strategy, err := s.testStrategyRepo.GetByID(ctx, strategyID)
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeTestStrategy)
 	if err != nil {
-		return "", "", errors.New("test strategy not found")
+		return "", "", err
 	}
 
-	var content string
-	var filename string
+	strategy, err := s.testStrategyRepo.GetByID(ctx, strategyID)
+	if err != nil {
+		return "", "", err
+	}
 
-	switch format {
-	case domain.ExportFormatMarkdown:
-		content, err = s.exporter.ExportTestStrategy(strategy, includeHistory, includeComments)
-		filename = fmt.Sprintf("test_strategy_%s_%s.md", strategy.Name, time.Now().Format("20060102_150405"))
-	default:
-		return "", "", errors.New("unsupported export format")
+	strategy.History, strategy.Comments, err = s.loadActivity(ctx, domain.EntityTypeTestStrategy, strategy.ID, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
 	}
 
+	content, err := exporter.ExportTestStrategy(strategy, includeHistory, includeComments)
 	if err != nil {
 		return "", "", err
 	}
 
-	return content, filename, nil
+	return content, exportFilename("test_strategy", strategy.Name, info), nil
 }
 
 func (s *exportService) ExportTestRun(ctx context.Context, testRunID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error) {
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeTestRun)
+	if err != nil {
+		return "", "", err
+	}
+
 	testRun, err := s.testRunRepo.GetByID(ctx, testRunID)
 	if err != nil {
-		return "", "", errors.New("test run not found")
+		return "", "", err
 	}
 
-	var content string
-	var filename string
+	testRun.History, testRun.Comments, err = s.loadActivity(ctx, domain.EntityTypeTestRun, testRun.ID, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
+	}
 
-	switch format {
-	case domain.ExportFormatMarkdown:
-		content, err = s.exporter.ExportTestRun(testRun, includeHistory, includeComments)
-		filename = fmt.Sprintf("test_run_%s_%s.md", testRun.Name, time.Now().Format("20060102_150405"))
-	default:
-		return "", "", errors.New("unsupported export format")
+	content, err := exporter.ExportTestRun(testRun, includeHistory, includeComments)
+	if err != nil {
+		return "", "", err
 	}
 
+	return content, exportFilename("test_run", testRun.Name, info), nil
+}
+
+// ExportProjectTestCases writes every test case of a project into one
+// document. Only formats whose exporter supports lists can be used.
+func (s *exportService) ExportProjectTestCases(ctx context.Context, projectID uuid.UUID, format domain.ExportFormat) (string, string, error) {
+	exporter, info, err := s.exporterFor(format, domain.EntityTypeTestCase)
 	if err != nil {
 		return "", "", err
 	}
+	listExporter, ok := exporter.(domain.TestCaseListExporter)
+	if !ok {
+		return "", "", errFormatNotAvailable(info, "test case lists")
+	}
+
+	const pageSize = 200
+	var testCases []domain.TestCase
+	for page := 1; ; page++ {
+		batch, total, err := s.testCaseRepo.List(ctx, projectID, domain.TestCaseFilter{}, page, pageSize)
+		if err != nil {
+			return "", "", err
+		}
+		testCases = append(testCases, batch...)
+		if len(batch) < pageSize || int64(len(testCases)) >= total {
+			break
+		}
+	}
+
+	content, err := listExporter.ExportTestCases(testCases)
+	if err != nil {
+		return "", "", err
+	}
+
+	return content, exportFilename("test_cases", projectID.String(), info), nil
+}
+
+func (s *exportService) ListFormats() []domain.ExportFormatInfo {
+	return s.exporters.Formats()
+}
+
+func (s *exportService) GetFormat(format domain.ExportFormat) (domain.ExportFormatInfo, error) {
+	_, info, ok := s.exporters.Get(format)
+	if !ok {
+		return domain.ExportFormatInfo{}, errUnsupportedExportFormat
+	}
+	return info, nil
+}
+
+// exporterFor looks up the exporter of a format and checks that it can
+// handle the entity type.
+func (s *exportService) exporterFor(format domain.ExportFormat, entityType string) (domain.Exporter, domain.ExportFormatInfo, error) {
+	exporter, info, ok := s.exporters.Get(format)
+	if !ok {
+		return nil, info, errUnsupportedExportFormat
+	}
+	if !info.Supports(entityType) {
+		return nil, info, errFormatNotAvailable(info, strings.ReplaceAll(entityType, "_", " "))
+	}
+	return exporter, info, nil
+}
+
+var errUnsupportedExportFormat = domain.Validation("unsupported_export_format", "unsupported export format",
+	domain.FieldError{Field: "format", Message: "is not a supported export format"})
+
+func errFormatNotAvailable(info domain.ExportFormatInfo, subject string) error {
+	message := fmt.Sprintf("%s export is not available for %s", info.Name, subject)
+	return domain.Validation("export_format_not_available", message, domain.FieldError{Field: "format", Message: message})
+}
+
+func exportFilename(prefix, name string, info domain.ExportFormatInfo) string {
+	return fmt.Sprintf("%s_%s_%s.%s", prefix, name, time.Now().Format("20060102_150405"), info.Extension)
+}
+
+// loadActivity fetches the change log and comment thread of an entity when the
+// export asks for them.
+func (s *exportService) loadActivity(ctx context.Context, entityType string, entityID uuid.UUID, includeHistory, includeComments bool) ([]domain.History, []domain.Comment, error) {
+	var history []domain.History
+	var comments []domain.Comment
+	var err error
+
+	if includeHistory {
+		history, err = s.historyRepo.ListAll(ctx, entityType, entityID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if includeComments {
+		comments, err = s.commentRepo.ListAll(ctx, entityType, entityID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	return content, filename, nil
+	return history, comments, nil
 }
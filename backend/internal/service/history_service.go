@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type historyService struct {
+	repo repository.HistoryRepository
+}
+
+func NewHistoryService(repo repository.HistoryRepository) HistoryService {
+	return &historyService{repo: repo}
+}
+
+// Record stores a history entry attributed to the actor carried by ctx.
+// Updates without any changed field are not recorded.
+func (s *historyService) Record(ctx context.Context, entityType string, entityID uuid.UUID, action string, changes map[string]domain.FieldChange) error {
+	if action == domain.HistoryActionUpdated && len(changes) == 0 {
+		return nil
+	}
+	if changes == nil {
+		changes = map[string]domain.FieldChange{}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Create(ctx, &domain.History{
+		ID:         uuid.New(),
+		EntityID:   entityID,
+		EntityType: entityType,
+		Action:     action,
+		Changes:    string(data),
+		ChangedBy:  domain.ActorFromContext(ctx),
+		ChangedAt:  time.Now(),
+	})
+}
+
+// RecordDiff diffs before and after and records the result.
+func (s *historyService) RecordDiff(ctx context.Context, entityType string, entityID uuid.UUID, action string, before, after interface{}) error {
+	changes, err := domain.Diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	return s.Record(ctx, entityType, entityID, action, changes)
+}
+
+func (s *historyService) ListHistory(ctx context.Context, entityType string, entityID uuid.UUID, page, size int) ([]domain.History, int64, error) {
+	return s.repo.List(ctx, entityType, entityID, page, size)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+var errInvalidInvitation = domain.Validation("invalid_invitation", "the invitation is invalid, expired or already used",
+	domain.FieldError{Field: "token", Message: "is invalid, expired or already used"})
+
+type invitationService struct {
+	repo     repository.InvitationRepository
+	userRepo repository.UserRepository
+	mailer   Mailer
+	appURL   string
+}
+
+func NewInvitationService(repo repository.InvitationRepository, userRepo repository.UserRepository, mailer Mailer, appURL string) InvitationService {
+	return &invitationService{
+		repo:     repo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		appURL:   appURL,
+	}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, invitation *domain.Invitation) error {
+	switch invitation.Role {
+	case domain.RoleAdmin, domain.RoleUser, domain.RoleGuest:
+	default:
+		return domain.Validation("invalid_role", "invalid role", domain.FieldError{Field: "role", Message: "must be one of admin, user or guest"})
+	}
+
+	_, err := s.userRepo.GetByEmail(ctx, invitation.Email)
+	if err == nil {
+		return domain.Conflict("user_exists", "user already exists")
+	}
+	if !domain.IsNotFound(err) {
+		return err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invitation.ID = uuid.New()
+	invitation.TokenHash = hashToken(token)
+	invitation.ExpiresAt = now.Add(invitationTTL)
+	invitation.CreatedAt = now
+
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return err
+	}
+
+	// Unlike the self-service mails, the admin needs to know the invitation did not go out
+	subject, body := invitationMail(accountLink(s.appURL, "/accept-invitation", token), invitation.Role)
+	return s.mailer.Send(ctx, invitation.Email, subject, body)
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context) ([]domain.Invitation, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	invitation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !invitation.Pending(time.Now()) {
+		return domain.Conflict("invitation_not_pending", "invitation has already been used, revoked or has expired")
+	}
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *invitationService) AcceptInvitation(ctx context.Context, token, password string) (*domain.User, error) {
+	invitation, err := s.repo.GetByTokenHash(ctx, hashToken(token))
+	if domain.IsNotFound(err) {
+		return nil, errInvalidInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !invitation.Pending(time.Now()) {
+		return nil, errInvalidInvitation
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// The invitee proved the address by following the mailed link
+	now := time.Now()
+	user := &domain.User{
+		ID:              uuid.New(),
+		Email:           invitation.Email,
+		Password:        hashedPassword,
+		Role:            invitation.Role,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.repo.Accept(ctx, invitation, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/AntVerkh/test-management-system/pkg/totp"
+	"github.com/google/uuid"
+)
+
+const (
+	// mfaIssuer is the account name authenticator apps show next to the user's email
+	mfaIssuer = "Test Management System"
+
+	recoveryCodeCount = 10
+
+	// After maxMFAAttempts invalid codes in a row the second factor is locked
+	// for mfaLockout, which keeps six digit codes from being guessed.
+	maxMFAAttempts = 5
+	mfaLockout     = 15 * time.Minute
+)
+
+var (
+	errInvalidMFACode = domain.Validation("invalid_mfa_code", "the authentication code is invalid",
+		domain.FieldError{Field: "code", Message: "is invalid or was already used"})
+	errMFANotEnabled     = domain.Conflict("mfa_not_enabled", "two-factor authentication is not enabled")
+	errMFAAlreadyEnabled = domain.Conflict("mfa_already_enabled", "two-factor authentication is already enabled")
+	errMFALocked         = domain.Forbidden("mfa_locked", "too many invalid codes, try again later")
+	errMFARequired       = domain.Forbidden("mfa_required", "two-factor authentication is required for your role")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type mfaService struct {
+	repo     repository.MFARepository
+	userRepo repository.UserRepository
+}
+
+func NewMFAService(repo repository.MFARepository, userRepo repository.UserRepository) MFAService {
+	return &mfaService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *mfaService) Status(ctx context.Context, user *domain.User) (*domain.MFAStatus, error) {
+	required, err := s.required(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MFAStatus{Required: required}
+
+	enrollment, err := s.repo.GetEnrollment(ctx, user.ID)
+	if domain.IsNotFound(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Confirmed() {
+		return status, nil
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = true
+	status.ConfirmedAt = enrollment.ConfirmedAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID uuid.UUID) (*domain.MFASetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetEnrollment(ctx, userID)
+	if err != nil && !domain.IsNotFound(err) {
+		return nil, err
+	}
+	if existing != nil && existing.Confirmed() {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment := &domain.MFAEnrollment{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.SaveEnrollment(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	return &domain.MFASetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	enrollment, err := s.repo.GetEnrollment(ctx, userID)
+	if domain.IsNotFound(err) {
+		return nil, domain.Conflict("mfa_not_enrolled", "start the enrollment before confirming it")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Confirmed() {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	// Recovery codes do not exist yet, the code has to come from the authenticator
+	if err := s.checkCode(ctx, enrollment, code, false); err != nil {
+		return nil, err
+	}
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.ConfirmEnrollment(ctx, userID, time.Now(), codes)
+	if domain.IsNotFound(err) {
+		return nil, errMFAAlreadyEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+func (s *mfaService) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	enrollment, err := s.confirmedEnrollment(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.checkCode(ctx, enrollment, code, true)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	enrollment, err := s.confirmedEnrollment(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A recovery code would be replaced by the new ones anyway, so require the authenticator
+	if err := s.checkCode(ctx, enrollment, code, false); err != nil {
+		return nil, err
+	}
+
+	plain, codes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.required(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errMFARequired
+	}
+
+	enrollment, err := s.confirmedEnrollment(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkCode(ctx, enrollment, code, true); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteEnrollment(ctx, userID)
+}
+
+func (s *mfaService) Reset(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	// If the role requires a second factor, the user enrolls again at the next login
+	return s.repo.DeleteEnrollment(ctx, userID)
+}
+
+func (s *mfaService) ListPolicies(ctx context.Context) ([]domain.MFAPolicy, error) {
+	stored, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[domain.UserRole]domain.MFAPolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	policies := make([]domain.MFAPolicy, 0, 3)
+	for _, role := range []domain.UserRole{domain.RoleAdmin, domain.RoleUser, domain.RoleGuest} {
+		policy, ok := byRole[role]
+		if !ok {
+			policy = domain.MFAPolicy{Role: role}
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+func (s *mfaService) SetPolicy(ctx context.Context, policy *domain.MFAPolicy) error {
+	switch policy.Role {
+	case domain.RoleAdmin, domain.RoleUser, domain.RoleGuest:
+	default:
+		return domain.Validation("invalid_role", "invalid role", domain.FieldError{Field: "role", Message: "must be one of admin, user or guest"})
+	}
+
+	policy.UpdatedAt = time.Now()
+	return s.repo.SavePolicy(ctx, policy)
+}
+
+func (s *mfaService) required(ctx context.Context, role domain.UserRole) (bool, error) {
+	policy, err := s.repo.GetPolicy(ctx, role)
+	if domain.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return policy.Required, nil
+}
+
+func (s *mfaService) confirmedEnrollment(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollment, error) {
+	enrollment, err := s.repo.GetEnrollment(ctx, userID)
+	if domain.IsNotFound(err) {
+		return nil, errMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Confirmed() {
+		return nil, errMFANotEnabled
+	}
+	return enrollment, nil
+}
+
+// checkCode accepts a current authenticator code or, when allowRecovery is
+// set, an unused recovery code, and counts invalid ones towards the lockout.
+func (s *mfaService) checkCode(ctx context.Context, enrollment *domain.MFAEnrollment, code string, allowRecovery bool) error {
+	now := time.Now()
+	if enrollment.LockedUntil != nil && now.Before(*enrollment.LockedUntil) {
+		return errMFALocked
+	}
+
+	code = normalizeMFACode(code)
+
+	var err error
+	switch {
+	case len(code) == totp.Digits:
+		step, ok := totp.Validate(enrollment.Secret, code, now)
+		if !ok {
+			err = errInvalidMFACode
+			break
+		}
+		err = s.repo.UseStep(ctx, enrollment.UserID, step)
+	case allowRecovery:
+		err = s.repo.UseRecoveryCode(ctx, enrollment.UserID, hashToken(code))
+	default:
+		err = errInvalidMFACode
+	}
+
+	if domain.IsNotFound(err) {
+		err = errInvalidMFACode
+	}
+	if err == errInvalidMFACode {
+		if failErr := s.repo.RecordFailure(ctx, enrollment.UserID, maxMFAAttempts, now.Add(mfaLockout)); failErr != nil {
+			return failErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if enrollment.FailedAttempts > 0 {
+		return s.repo.ClearFailures(ctx, enrollment.UserID)
+	}
+	return nil
+}
+
+// newRecoveryCodes returns recovery codes formatted for the user together
+// with the records storing their hashes.
+func newRecoveryCodes(userID uuid.UUID) ([]string, []domain.MFARecoveryCode, error) {
+	now := time.Now()
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]domain.MFARecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		// 50 random bits as ten characters, shown as two groups of five
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+
+		plain = append(plain, code[:5]+"-"+code[5:])
+		codes = append(codes, domain.MFARecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  hashToken(code),
+			CreatedAt: now,
+		})
+	}
+
+	return plain, codes, nil
+}
+
+// normalizeMFACode drops the spacing users type or copy along with a code.
+func normalizeMFACode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
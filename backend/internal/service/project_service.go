@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrProjectAccessDenied is returned when the caller is not a member of the
+// project or their project role is too low for the operation.
+var ErrProjectAccessDenied = domain.Forbidden("insufficient_project_permissions", "insufficient project permissions")
+
+type projectService struct {
+	repo     repository.ProjectRepository
+	userRepo repository.UserRepository
+}
+
+func NewProjectService(repo repository.ProjectRepository, userRepo repository.UserRepository) ProjectService {
+	return &projectService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *projectService) CreateProject(ctx context.Context, project *domain.Project) error {
+	project.ID = uuid.New()
+	project.CreatedAt = time.Now()
+
+	// The creator always becomes the first owner
+	project.Members = []domain.ProjectMember{{
+		ProjectID: project.ID,
+		UserID:    project.CreatedBy,
+		Role:      domain.ProjectRoleOwner,
+		CreatedAt: time.Now(),
+	}}
+
+	return s.repo.Create(ctx, project)
+}
+
+func (s *projectService) GetProject(ctx context.Context, id uuid.UUID) (*domain.Project, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *projectService) UpdateProject(ctx context.Context, project *domain.Project) error {
+	return s.repo.Update(ctx, project)
+}
+
+func (s *projectService) ListProjects(ctx context.Context, userID uuid.UUID, userRole domain.UserRole, page, size int) ([]domain.Project, int64, error) {
+	if userRole == domain.RoleAdmin {
+		return s.repo.List(ctx, page, size)
+	}
+	return s.repo.ListByMember(ctx, userID, page, size)
+}
+
+func (s *projectService) ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error) {
+	return s.repo.ListMembers(ctx, projectID)
+}
+
+func (s *projectService) SetMember(ctx context.Context, projectID, userID uuid.UUID, role domain.ProjectRole) (*domain.ProjectMember, error) {
+	if !role.Valid() {
+		return nil, domain.Validation("invalid_project_role", "invalid project role",
+			domain.FieldError{Field: "role", Message: "must be one of owner, editor or viewer"})
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMember(ctx, projectID, userID)
+	if err != nil {
+		if !domain.IsNotFound(err) {
+			return nil, err
+		}
+		if user.IsServiceAccount {
+			return nil, domain.Conflict("service_account_project", "service accounts belong to a single project")
+		}
+		member = &domain.ProjectMember{
+			ProjectID: projectID,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		}
+	} else if member.Role == domain.ProjectRoleOwner && role != domain.ProjectRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.repo.SaveMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *projectService) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	member, err := s.repo.GetMember(ctx, projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == domain.ProjectRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, projectID); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.RemoveMember(ctx, projectID, userID)
+}
+
+// CheckAccess verifies that the user holds at least the required role in the
+// project. Global admins are allowed everywhere.
+func (s *projectService) CheckAccess(ctx context.Context, projectID, userID uuid.UUID, userRole domain.UserRole, required domain.ProjectRole) error {
+	if userRole == domain.RoleAdmin {
+		return nil
+	}
+
+	member, err := s.repo.GetMember(ctx, projectID, userID)
+	if domain.IsNotFound(err) {
+		return ErrProjectAccessDenied
+	}
+	if err != nil {
+		return err
+	}
+
+	if !member.Role.Includes(required) {
+		return ErrProjectAccessDenied
+	}
+
+	return nil
+}
+
+func (s *projectService) GetEntityProjectID(ctx context.Context, entityType string, entityID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.GetEntityProjectID(ctx, entityType, entityID)
+}
+
+func (s *projectService) ensureAnotherOwner(ctx context.Context, projectID uuid.UUID) error {
+	owners, err := s.repo.CountOwners(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return domain.Conflict("last_project_owner", "project must keep at least one owner")
+	}
+	return nil
+}
@@ -2,16 +2,49 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/pkg/oidc"
 	"github.com/google/uuid"
 )
 
 // AuthService interface
 type AuthService interface {
-	Login(ctx context.Context, email, password string) (string, *domain.User, error)
+	// Login checks the password and either starts a session or, when the user
+	// has or needs a second factor, returns an MFA token to continue with.
+	Login(ctx context.Context, email, password string) (*domain.LoginResult, error)
+	// CompleteMFALogin starts the session of an MFA token with an authenticator or recovery code.
+	CompleteMFALogin(ctx context.Context, mfaToken, code string) (*domain.LoginResult, error)
+	// EnrollMFALogin and ConfirmMFALogin set up the second factor during a
+	// login that requires one; confirming starts the session.
+	EnrollMFALogin(ctx context.Context, mfaToken string) (*domain.MFASetup, error)
+	ConfirmMFALogin(ctx context.Context, mfaToken, code string) (*domain.LoginResult, error)
+	SSOInfo() *domain.SSOInfo
+	// StartSSOLogin records a sign-in attempt and returns the identity
+	// provider URL to send the browser to.
+	StartSSOLogin(ctx context.Context) (string, error)
+	// CompleteSSOLogin redeems the code the provider returned with, signing the
+	// user in or creating their account; like Login it may ask for a second factor.
+	CompleteSSOLogin(ctx context.Context, code, state string) (*domain.LoginResult, error)
+	// Register creates an unverified account and mails a verification link.
 	Register(ctx context.Context, user *domain.User) error
-	ValidateToken(ctx context.Context, token string) (*domain.User, error)
+	VerifyEmail(ctx context.Context, token string) error
+	// ResendVerification mails a new link if the address belongs to an
+	// unverified account; it reports nothing about whether it does.
+	ResendVerification(ctx context.Context, email string) error
+	// ChangePassword revokes all of the user's sessions and starts a new one for the caller.
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) (*domain.AuthTokens, error)
+	// RequestPasswordReset mails a reset link if the address belongs to an
+	// account; it reports nothing about whether it does.
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	// Refresh exchanges a refresh token for a new token pair, rotating the refresh token.
+	Refresh(ctx context.Context, refreshToken string) (*domain.AuthTokens, error)
+	Logout(ctx context.Context, sessionID uuid.UUID) error
+	// ValidateToken returns the user and session of an access token whose session is still active.
+	ValidateToken(ctx context.Context, token string) (*domain.User, uuid.UUID, error)
 }
 
 // UserService interface
@@ -21,11 +54,76 @@ type UserService interface {
 	UpdateUserRole(ctx context.Context, userID uuid.UUID, role domain.UserRole) error
 }
 
+// APITokenService interface
+type APITokenService interface {
+	// CreateToken stores the token and returns its plain value, which is not
+	// kept; a lifetime of zero uses the default.
+	CreateToken(ctx context.Context, token *domain.APIToken, lifetime time.Duration) (string, error)
+	GetToken(ctx context.Context, id uuid.UUID) (*domain.APIToken, error)
+	// ListTokens lists the tokens of a user, or of all users when userID is uuid.Nil.
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error)
+	RevokeToken(ctx context.Context, id uuid.UUID) error
+	// Authenticate resolves an active API token to its user and records its use.
+	Authenticate(ctx context.Context, token string) (*domain.User, *domain.APIToken, error)
+}
+
+// ServiceAccountService interface
+type ServiceAccountService interface {
+	CreateServiceAccount(ctx context.Context, account *domain.ServiceAccount) error
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context, projectID uuid.UUID) ([]domain.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) error
+}
+
+// MFAService interface
+type MFAService interface {
+	Status(ctx context.Context, user *domain.User) (*domain.MFAStatus, error)
+	// Enroll starts a new enrollment, replacing an unconfirmed one.
+	Enroll(ctx context.Context, userID uuid.UUID) (*domain.MFASetup, error)
+	// Confirm puts the enrollment in effect with a first authenticator code
+	// and returns the recovery codes, which are not kept in plain text.
+	Confirm(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// Verify accepts an authenticator code or an unused recovery code, each only once.
+	Verify(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// Disable removes the user's own enrollment unless their role requires one.
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+	// Reset removes a user's enrollment for an admin, e.g. after a lost device.
+	Reset(ctx context.Context, userID uuid.UUID) error
+	// ListPolicies returns the policy of every role, including roles without a stored one.
+	ListPolicies(ctx context.Context) ([]domain.MFAPolicy, error)
+	SetPolicy(ctx context.Context, policy *domain.MFAPolicy) error
+}
+
+// InvitationService interface
+type InvitationService interface {
+	// CreateInvitation mails the invitation, replacing any pending one for the same email.
+	CreateInvitation(ctx context.Context, invitation *domain.Invitation) error
+	ListInvitations(ctx context.Context) ([]domain.Invitation, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) error
+	// AcceptInvitation creates the invited account, already verified, with the given password.
+	AcceptInvitation(ctx context.Context, token, password string) (*domain.User, error)
+}
+
+// ProjectService interface
+type ProjectService interface {
+	CreateProject(ctx context.Context, project *domain.Project) error
+	GetProject(ctx context.Context, id uuid.UUID) (*domain.Project, error)
+	UpdateProject(ctx context.Context, project *domain.Project) error
+	ListProjects(ctx context.Context, userID uuid.UUID, userRole domain.UserRole, page, size int) ([]domain.Project, int64, error)
+	ListMembers(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectMember, error)
+	SetMember(ctx context.Context, projectID, userID uuid.UUID, role domain.ProjectRole) (*domain.ProjectMember, error)
+	RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error
+	CheckAccess(ctx context.Context, projectID, userID uuid.UUID, userRole domain.UserRole, required domain.ProjectRole) error
+	GetEntityProjectID(ctx context.Context, entityType string, entityID uuid.UUID) (uuid.UUID, error)
+}
+
 // TestPlanService interface
 type TestPlanService interface {
 	CreateTestPlan(ctx context.Context, plan *domain.TestPlan) error
 	GetTestPlan(ctx context.Context, id uuid.UUID) (*domain.TestPlan, error)
 	UpdateTestPlan(ctx context.Context, plan *domain.TestPlan) error
+	DeleteTestPlan(ctx context.Context, id uuid.UUID) error
 	ListTestPlans(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestPlan, int64, error)
 	AddTestCaseToPlan(ctx context.Context, planID, testCaseID uuid.UUID) error
 	AddChecklistToPlan(ctx context.Context, planID, checklistID uuid.UUID) error
@@ -36,7 +134,36 @@ type TestCaseService interface {
 	CreateTestCase(ctx context.Context, testCase *domain.TestCase) error
 	GetTestCase(ctx context.Context, id uuid.UUID) (*domain.TestCase, error)
 	UpdateTestCase(ctx context.Context, testCase *domain.TestCase) error
-	ListTestCases(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestCase, int64, error)
+	DeleteTestCase(ctx context.Context, id uuid.UUID) error
+	ListTestCases(ctx context.Context, projectID uuid.UUID, filter domain.TestCaseFilter, page, size int) ([]domain.TestCase, int64, error)
+	GetAutomationCoverage(ctx context.Context, projectID uuid.UUID) (*domain.AutomationCoverage, error)
+	ImportTestCases(ctx context.Context, projectID, createdBy uuid.UUID, format domain.ImportFormat, data []byte, mapping domain.ImportMapping, dryRun bool) (*domain.ImportReport, error)
+	AddStep(ctx context.Context, step *domain.TestStep) error
+	UpdateStep(ctx context.Context, step *domain.TestStep) error
+	RemoveStep(ctx context.Context, testCaseID, stepID uuid.UUID) error
+	MoveStep(ctx context.Context, testCaseID, stepID uuid.UUID, order int) error
+}
+
+// ChecklistService interface
+type ChecklistService interface {
+	CreateChecklist(ctx context.Context, checklist *domain.Checklist) error
+	GetChecklist(ctx context.Context, id uuid.UUID) (*domain.Checklist, error)
+	UpdateChecklist(ctx context.Context, checklist *domain.Checklist) error
+	DeleteChecklist(ctx context.Context, id uuid.UUID) error
+	ListChecklists(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.Checklist, int64, error)
+	AddItem(ctx context.Context, item *domain.ChecklistItem) error
+	UpdateItem(ctx context.Context, item *domain.ChecklistItem) error
+	RemoveItem(ctx context.Context, checklistID, itemID uuid.UUID) error
+	ReorderItems(ctx context.Context, checklistID uuid.UUID, itemIDs []uuid.UUID) error
+}
+
+// TestStrategyService interface
+type TestStrategyService interface {
+	CreateTestStrategy(ctx context.Context, strategy *domain.TestStrategy) error
+	GetTestStrategy(ctx context.Context, id uuid.UUID) (*domain.TestStrategy, error)
+	UpdateTestStrategy(ctx context.Context, strategy *domain.TestStrategy) error
+	DeleteTestStrategy(ctx context.Context, id uuid.UUID) error
+	ListTestStrategies(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestStrategy, int64, error)
 }
 
 // TestRunService interface
@@ -44,13 +171,62 @@ type TestRunService interface {
 	StartTestRun(ctx context.Context, testRun *domain.TestRun) error
 	RecordTestResult(ctx context.Context, result *domain.TestResult) error
 	GetTestRun(ctx context.Context, id uuid.UUID) (*domain.TestRun, error)
+	DeleteTestRun(ctx context.Context, id uuid.UUID) error
+	ListTestRuns(ctx context.Context, testPlanID uuid.UUID, page, size int) ([]domain.TestRun, int64, error)
 	CompleteTestRun(ctx context.Context, id uuid.UUID) error
+	IngestResults(ctx context.Context, req *domain.IngestRequest) (*domain.IngestReport, error)
+}
+
+// TrashService interface
+type TrashService interface {
+	ListTrash(ctx context.Context, projectID uuid.UUID, entityType string, page, size int) ([]domain.TrashItem, int64, error)
+	RestoreItem(ctx context.Context, projectID uuid.UUID, entityType string, id uuid.UUID) error
+	Purge(ctx context.Context, olderThan time.Duration) (*domain.PurgeReport, error)
+	Retention() time.Duration
+}
+
+// HistoryService interface
+type HistoryService interface {
+	Record(ctx context.Context, entityType string, entityID uuid.UUID, action string, changes map[string]domain.FieldChange) error
+	RecordDiff(ctx context.Context, entityType string, entityID uuid.UUID, action string, before, after interface{}) error
+	ListHistory(ctx context.Context, entityType string, entityID uuid.UUID, page, size int) ([]domain.History, int64, error)
+}
+
+// CommentService interface
+type CommentService interface {
+	CreateComment(ctx context.Context, comment *domain.Comment) error
+	GetComment(ctx context.Context, id uuid.UUID) (*domain.Comment, error)
+	UpdateComment(ctx context.Context, comment *domain.Comment) error
+	DeleteComment(ctx context.Context, id uuid.UUID) error
+	ListComments(ctx context.Context, entityType string, entityID uuid.UUID, resolved *bool, page, size int) ([]domain.Comment, int64, error)
+	SetResolved(ctx context.Context, id, userID uuid.UUID, resolved bool) (*domain.Comment, error)
+}
+
+// AttachmentService interface
+type AttachmentService interface {
+	Upload(ctx context.Context, attachment *domain.Attachment, file io.Reader) error
+	GetAttachment(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+	Open(ctx context.Context, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, id uuid.UUID) error
+}
+
+// Mailer interface
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// OIDCProvider interface
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Identity, error)
 }
 
 // JWTService interface
 type JWTService interface {
-	GenerateToken(user *domain.User) (string, error)
-	ValidateToken(token string) (*domain.User, error)
+	GenerateToken(user *domain.User, sessionID uuid.UUID) (string, time.Time, error)
+	ValidateToken(token string) (*domain.User, uuid.UUID, error)
+	GenerateChallenge(userID uuid.UUID) (string, error)
+	ValidateChallenge(token string) (uuid.UUID, error)
 }
 
 // ExportService interface
@@ -61,4 +237,7 @@ type ExportService interface {
 	ExportChecklist(ctx context.Context, checklistID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error)
 	ExportTestStrategy(ctx context.Context, strategyID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error)
 	ExportTestRun(ctx context.Context, testRunID uuid.UUID, format domain.ExportFormat, includeHistory, includeComments bool) (string, string, error)
+	ExportProjectTestCases(ctx context.Context, projectID uuid.UUID, format domain.ExportFormat) (string, string, error)
+	ListFormats() []domain.ExportFormatInfo
+	GetFormat(format domain.ExportFormat) (domain.ExportFormatInfo, error)
 }
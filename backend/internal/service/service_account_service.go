@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type serviceAccountService struct {
+	repo repository.ServiceAccountRepository
+}
+
+func NewServiceAccountService(repo repository.ServiceAccountRepository) ServiceAccountService {
+	return &serviceAccountService{repo: repo}
+}
+
+func (s *serviceAccountService) CreateServiceAccount(ctx context.Context, account *domain.ServiceAccount) error {
+	if account.Role == "" {
+		account.Role = domain.ProjectRoleEditor
+	}
+	if !account.Role.Valid() {
+		return domain.Validation("invalid_project_role", "invalid project role",
+			domain.FieldError{Field: "role", Message: "must be one of owner, editor or viewer"})
+	}
+
+	now := time.Now()
+	account.ID = uuid.New()
+	account.UserID = uuid.New()
+	account.CreatedAt = now
+
+	// The user has no password, so it cannot log in; the reserved .invalid
+	// domain keeps its address from ever matching a real one.
+	user := &domain.User{
+		ID:               account.UserID,
+		Email:            "service-account-" + account.ID.String() + "@service-accounts.invalid",
+		Role:             domain.RoleUser,
+		IsServiceAccount: true,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	return s.repo.Create(ctx, account, user)
+}
+
+func (s *serviceAccountService) GetServiceAccount(ctx context.Context, id uuid.UUID) (*domain.ServiceAccount, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *serviceAccountService) ListServiceAccounts(ctx context.Context, projectID uuid.UUID) ([]domain.ServiceAccount, error) {
+	return s.repo.ListByProject(ctx, projectID)
+}
+
+func (s *serviceAccountService) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, account)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/pkg/oidc"
+	"github.com/google/uuid"
+)
+
+// oidcRequestTTL is how long a user has to sign in at the identity provider
+const oidcRequestTTL = 10 * time.Minute
+
+var (
+	errSSODisabled     = domain.NotFound("single sign on")
+	errInvalidSSOState = domain.Validation("invalid_state", "the sign-in attempt is invalid or has expired, start again",
+		domain.FieldError{Field: "state", Message: "is invalid, expired or already used"})
+	errSSOFailed           = domain.Unauthorized("sso_failed", "single sign-on failed")
+	errSSOEmailNotVerified = domain.Forbidden("sso_email_not_verified", "the email address is not verified at the identity provider")
+)
+
+// SSOConfig configures single sign-on through an OpenID Connect provider.
+type SSOConfig struct {
+	Provider     OIDCProvider // nil when single sign-on is off
+	ProviderName string
+	// RoleMapping maps provider groups to roles; the highest role of the user's groups wins
+	RoleMapping map[string]domain.UserRole
+	// DefaultRole is given to new users in no mapped group; when empty they are refused
+	DefaultRole domain.UserRole
+	// TrustUnverifiedEmail links existing accounts to identities whose email
+	// the provider does not report as verified
+	TrustUnverifiedEmail bool
+}
+
+// roleRank orders roles so the highest of several mapped groups can be picked.
+var roleRank = map[domain.UserRole]int{
+	domain.RoleGuest: 1,
+	domain.RoleUser:  2,
+	domain.RoleAdmin: 3,
+}
+
+func (s *authService) SSOInfo() *domain.SSOInfo {
+	if s.sso.Provider == nil {
+		return &domain.SSOInfo{}
+	}
+	return &domain.SSOInfo{Enabled: true, ProviderName: s.sso.ProviderName}
+}
+
+func (s *authService) StartSSOLogin(ctx context.Context) (string, error) {
+	if s.sso.Provider == nil {
+		return "", errSSODisabled
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := s.sso.Provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		log.Printf("sso: %v", err)
+		return "", errSSOFailed
+	}
+
+	now := time.Now()
+	request := &domain.OIDCAuthRequest{
+		ID:           uuid.New(),
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oidcRequestTTL),
+		CreatedAt:    now,
+	}
+	if err := s.oidcRequestRepo.Create(ctx, request); err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+func (s *authService) CompleteSSOLogin(ctx context.Context, code, state string) (*domain.LoginResult, error) {
+	if s.sso.Provider == nil {
+		return nil, errSSODisabled
+	}
+
+	request, err := s.oidcRequestRepo.Consume(ctx, hashToken(state))
+	if domain.IsNotFound(err) {
+		return nil, errInvalidSSOState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Provider errors are logged rather than returned, they may describe its configuration
+	identity, err := s.sso.Provider.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		log.Printf("sso: %v", err)
+		return nil, errSSOFailed
+	}
+
+	user, err := s.ssoUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// A second factor enrolled here is asked for just like after a password
+	return s.authenticated(ctx, user)
+}
+
+// ssoUser returns the account for the identity, matched by email, creating it
+// on first sign-in. Mapped groups also update the role of existing accounts.
+func (s *authService) ssoUser(ctx context.Context, identity *oidc.Identity) (*domain.User, error) {
+	if identity.Email == "" {
+		return nil, domain.Forbidden("sso_email_missing", "the identity provider did not share an email address")
+	}
+
+	if identity.EmailVerified != nil && !*identity.EmailVerified {
+		return nil, errSSOEmailNotVerified
+	}
+
+	role, mapped := s.ssoRole(identity.Groups)
+
+	user, err := s.userRepo.GetByEmail(ctx, identity.Email)
+	if domain.IsNotFound(err) {
+		return s.provisionSSOUser(ctx, identity, role, mapped)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts are matched by email, so an existing one is only linked to an
+	// address the provider has verified, unless configured to trust any
+	verified := identity.EmailVerified != nil && *identity.EmailVerified
+	if !verified && !s.sso.TrustUnverifiedEmail {
+		return nil, errSSOEmailNotVerified
+	}
+
+	if user.IsServiceAccount {
+		return nil, errInvalidCredentials
+	}
+
+	roleChanged := mapped && user.Role != role
+	if !roleChanged && user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+	if roleChanged {
+		user.Role = role
+	}
+	user.UpdatedAt = now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// As with an admin changing the role, tokens carrying the old one must go
+	if roleChanged {
+		if err := s.sessionRepo.RevokeAllForUser(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (s *authService) provisionSSOUser(ctx context.Context, identity *oidc.Identity, role domain.UserRole, mapped bool) (*domain.User, error) {
+	if !mapped {
+		if s.sso.DefaultRole == "" {
+			return nil, domain.Forbidden("sso_no_role", "your account is not in a group that has access")
+		}
+		role = s.sso.DefaultRole
+	}
+
+	// Without a password the account can only sign in through the provider,
+	// until the user sets one with a password reset
+	now := time.Now()
+	user := &domain.User{
+		ID:              uuid.New(),
+		Email:           identity.Email,
+		Role:            role,
+		EmailVerifiedAt: &now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ssoRole returns the highest role mapped from the groups and whether any group was mapped.
+func (s *authService) ssoRole(groups []string) (domain.UserRole, bool) {
+	var role domain.UserRole
+	for _, group := range groups {
+		mapped, ok := s.sso.RoleMapping[group]
+		if ok && roleRank[mapped] > roleRank[role] {
+			role = mapped
+		}
+	}
+	return role, role != ""
+}
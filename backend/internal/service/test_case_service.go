@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/AntVerkh/test-management-system/internal/repository"
@@ -10,11 +12,17 @@ import (
 )
 
 type testCaseService struct {
-	repo repository.TestCaseRepository
+	repo    repository.TestCaseRepository
+	history HistoryService
+	tx      repository.Transactor
 }
 
-func NewTestCaseService(repo repository.TestCaseRepository) TestCaseService {
-	return &testCaseService{repo: repo}
+func NewTestCaseService(repo repository.TestCaseRepository, history HistoryService, tx repository.Transactor) TestCaseService {
+	return &testCaseService{
+		repo:    repo,
+		history: history,
+		tx:      tx,
+	}
 }
 
 func (s *testCaseService) CreateTestCase(ctx context.Context, testCase *domain.TestCase) error {
@@ -27,8 +35,19 @@ func (s *testCaseService) CreateTestCase(ctx context.Context, testCase *domain.T
 		testCase.Steps[i].ID = uuid.New()
 		testCase.Steps[i].CreatedAt = time.Now()
 	}
+	domain.NumberSteps(testCase.Steps)
+
+	if err := s.validateAutomation(ctx, testCase); err != nil {
+		return err
+	}
 
-	return s.repo.Create(ctx, testCase)
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, testCase); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestCase, testCase.ID, domain.HistoryActionCreated, nil, testCase)
+	})
 }
 
 func (s *testCaseService) GetTestCase(ctx context.Context, id uuid.UUID) (*domain.TestCase, error) {
@@ -36,10 +55,338 @@ func (s *testCaseService) GetTestCase(ctx context.Context, id uuid.UUID) (*domai
 }
 
 func (s *testCaseService) UpdateTestCase(ctx context.Context, testCase *domain.TestCase) error {
+	before, err := s.repo.GetByID(ctx, testCase.ID)
+	if err != nil {
+		return err
+	}
+	if before.Version != testCase.Version {
+		return domain.VersionConflict("test case", before)
+	}
+
+	if err := s.validateAutomation(ctx, testCase); err != nil {
+		return err
+	}
+	if err := syncSteps(before, testCase); err != nil {
+		return err
+	}
+
 	testCase.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, testCase)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, testCase); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestCase, testCase.ID, domain.HistoryActionUpdated, before, testCase)
+	})
+	return withCurrent(err, "test case", func() (interface{}, error) {
+		return s.repo.GetByID(ctx, testCase.ID)
+	})
+}
+
+// syncSteps prepares testCase.Steps to replace the steps of before: steps
+// with an ID must be existing steps of the test case and keep their creation
+// time, steps without one are new. Positions follow Order and are renumbered.
+func syncSteps(before, testCase *domain.TestCase) error {
+	existing := make(map[uuid.UUID]domain.TestStep, len(before.Steps))
+	for _, step := range before.Steps {
+		existing[step.ID] = step
+	}
+
+	now := time.Now()
+	seen := make(map[uuid.UUID]bool, len(testCase.Steps))
+	for i := range testCase.Steps {
+		step := &testCase.Steps[i]
+		step.TestCaseID = testCase.ID
+		if step.ID == uuid.Nil {
+			step.ID = uuid.New()
+			step.CreatedAt = now
+			continue
+		}
+
+		stored, ok := existing[step.ID]
+		if !ok || seen[step.ID] {
+			return domain.Validation("invalid_steps", "steps may only repeat existing steps of the test case, each once",
+				domain.FieldError{Field: fmt.Sprintf("steps[%d].id", i), Message: "must be a step of this test case, listed once"})
+		}
+		seen[step.ID] = true
+		step.CreatedAt = stored.CreatedAt
+	}
+
+	domain.NumberSteps(testCase.Steps)
+	return nil
+}
+
+// DeleteTestCase moves the test case to the trash.
+func (s *testCaseService) DeleteTestCase(ctx context.Context, id uuid.UUID) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, domain.ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestCase, id, domain.HistoryActionDeleted, nil)
+	})
+}
+
+func (s *testCaseService) ListTestCases(ctx context.Context, projectID uuid.UUID, filter domain.TestCaseFilter, page, size int) ([]domain.TestCase, int64, error) {
+	return s.repo.List(ctx, projectID, filter, page, size)
+}
+
+func (s *testCaseService) GetAutomationCoverage(ctx context.Context, projectID uuid.UUID) (*domain.AutomationCoverage, error) {
+	return s.repo.GetAutomationCoverage(ctx, projectID)
+}
+
+// validateAutomation defaults the automation status, links the automation
+// identifiers to the test case and checks that no other test case of the
+// project uses them.
+func (s *testCaseService) validateAutomation(ctx context.Context, testCase *domain.TestCase) error {
+	if testCase.AutomationStatus == "" {
+		testCase.AutomationStatus = domain.AutomationStatusManual
+	}
+	if !domain.ValidAutomationStatus(testCase.AutomationStatus) {
+		return domain.Validation("invalid_automation_status", "invalid automation status",
+			domain.FieldError{Field: "automation_status", Message: "must be one of manual, automated or to_be_automated"})
+	}
+
+	testCase.SetAutomationKeys(testCase.AutomationKeys())
+	keys := testCase.AutomationKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if utf8.RuneCountInString(key) > domain.MaxAutomationKeyLength {
+			return domain.Validation("invalid_automation_id", "automation identifier is too long",
+				domain.FieldError{Field: "automation_ids", Message: fmt.Sprintf("must be at most %d characters", domain.MaxAutomationKeyLength)})
+		}
+	}
+
+	existing, err := s.repo.FindAutomationIDs(ctx, testCase.ProjectID, keys)
+	if err != nil {
+		return err
+	}
+	for _, id := range existing {
+		if id.TestCaseID != testCase.ID {
+			return domain.Conflict("automation_identifier_exists", fmt.Sprintf("automation identifier %q is already used by another test case", id.Key))
+		}
+	}
+	return nil
+}
+
+// ImportTestCases creates the valid test cases of an import file in a single
+// transaction. With dryRun set the file is only validated.
+func (s *testCaseService) ImportTestCases(ctx context.Context, projectID, createdBy uuid.UUID, format domain.ImportFormat, data []byte, mapping domain.ImportMapping, dryRun bool) (*domain.ImportReport, error) {
+	imported, report, err := domain.ParseImport(format, data, mapping)
+	if err != nil {
+		return nil, domain.Validation("invalid_import_file", err.Error())
+	}
+	report.DryRun = dryRun
+
+	if imported, err = s.skipKnownAutomationKeys(ctx, projectID, imported, report); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	testCases := make([]domain.TestCase, len(imported))
+	for i, item := range imported {
+		testCase := item.TestCase
+		testCase.ID = uuid.New()
+		testCase.ProjectID = projectID
+		testCase.CreatedBy = createdBy
+		testCase.CreatedAt = now
+		testCase.UpdatedAt = now
+		testCase.SetAutomationKeys(testCase.AutomationKeys())
+		for j := range testCase.Steps {
+			testCase.Steps[j].ID = uuid.New()
+			testCase.Steps[j].TestCaseID = testCase.ID
+			testCase.Steps[j].CreatedAt = now
+		}
+		domain.NumberSteps(testCase.Steps)
+		testCases[i] = testCase
+
+		report.CreatedRows += len(item.Rows)
+		report.StepsCreated += len(testCase.Steps)
+	}
+	report.TestCasesCreated = len(testCases)
+
+	if dryRun || len(testCases) == 0 {
+		return report, nil
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateBatch(ctx, testCases); err != nil {
+			return err
+		}
+
+		for i := range testCases {
+			if err := s.history.RecordDiff(ctx, domain.EntityTypeTestCase, testCases[i].ID, domain.HistoryActionCreated, nil, &testCases[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range testCases {
+		report.TestCaseIDs = append(report.TestCaseIDs, testCases[i].ID)
+	}
+	return report, nil
+}
+
+// skipKnownAutomationKeys drops imported test cases with an automation
+// identifier already used in the project or earlier in the same file, since
+// an identifier must point to a single test case.
+func (s *testCaseService) skipKnownAutomationKeys(ctx context.Context, projectID uuid.UUID, imported []domain.ImportedTestCase, report *domain.ImportReport) ([]domain.ImportedTestCase, error) {
+	var keys []string
+	for _, item := range imported {
+		keys = append(keys, item.TestCase.AutomationKeys()...)
+	}
+	if len(keys) == 0 {
+		return imported, nil
+	}
+
+	existing, err := s.repo.FindAutomationIDs(ctx, projectID, keys)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		used[id.Key] = true
+	}
+
+	kept := imported[:0]
+	for _, item := range imported {
+		duplicate := ""
+		for _, key := range item.TestCase.AutomationKeys() {
+			if used[key] {
+				duplicate = key
+				break
+			}
+		}
+		if duplicate != "" {
+			for _, row := range item.Rows {
+				report.Skipped = append(report.Skipped, domain.ImportRowIssue{
+					Row:     row,
+					Field:   "automation_ids",
+					Message: fmt.Sprintf("a test case with automation identifier %q already exists", duplicate),
+				})
+			}
+			continue
+		}
+
+		for _, key := range item.TestCase.AutomationKeys() {
+			used[key] = true
+		}
+		kept = append(kept, item)
+	}
+	return kept, nil
+}
+
+// AddStep inserts the step at the requested 1-based position, or appends it
+// when the position is missing or out of range.
+func (s *testCaseService) AddStep(ctx context.Context, step *domain.TestStep) error {
+	testCase, err := s.repo.GetByID(ctx, step.TestCaseID)
+	if err != nil {
+		return err
+	}
+
+	if step.Order < 1 || step.Order > len(testCase.Steps)+1 {
+		step.Order = len(testCase.Steps) + 1
+	}
+	step.ID = uuid.New()
+	step.CreatedAt = time.Now()
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddStep(ctx, step); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestCase, step.TestCaseID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"steps": {New: step},
+		})
+	})
+}
+
+func (s *testCaseService) UpdateStep(ctx context.Context, step *domain.TestStep) error {
+	existing, err := s.getStep(ctx, step.TestCaseID, step.ID)
+	if err != nil {
+		return err
+	}
+
+	// Position changes go through MoveStep
+	step.Order = existing.Order
+	step.CreatedAt = existing.CreatedAt
+
+	changes, err := domain.Diff(existing, step)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateStep(ctx, step); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestCase, step.TestCaseID, domain.HistoryActionUpdated,
+			domain.NestChanges("steps."+step.ID.String(), changes))
+	})
+}
+
+func (s *testCaseService) RemoveStep(ctx context.Context, testCaseID, stepID uuid.UUID) error {
+	step, err := s.getStep(ctx, testCaseID, stepID)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteStep(ctx, step); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestCase, testCaseID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"steps": {Old: step},
+		})
+	})
 }
 
-func (s *testCaseService) ListTestCases(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestCase, int64, error) {
-	return s.repo.List(ctx, projectID, page, size)
+// MoveStep moves the step to the 1-based position order; positions past the
+// end move it to the end.
+func (s *testCaseService) MoveStep(ctx context.Context, testCaseID, stepID uuid.UUID, order int) error {
+	testCase, err := s.repo.GetByID(ctx, testCaseID)
+	if err != nil {
+		return err
+	}
+	step, err := s.getStep(ctx, testCaseID, stepID)
+	if err != nil {
+		return err
+	}
+
+	if order > len(testCase.Steps) {
+		order = len(testCase.Steps)
+	}
+	if order == step.Order {
+		return nil
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.MoveStep(ctx, step, order); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestCase, testCaseID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"steps." + stepID.String() + ".order": {Old: step.Order, New: order},
+		})
+	})
+}
+
+// getStep loads a step of the test case; steps of other test cases are not found.
+func (s *testCaseService) getStep(ctx context.Context, testCaseID, stepID uuid.UUID) (*domain.TestStep, error) {
+	step, err := s.repo.GetStep(ctx, stepID)
+	if err != nil {
+		return nil, err
+	}
+	if step.TestCaseID != testCaseID {
+		return nil, domain.NotFound("test step")
+	}
+	return step, nil
 }
@@ -10,11 +10,21 @@ import (
 )
 
 type testPlanService struct {
-	repo repository.TestPlanRepository
+	repo          repository.TestPlanRepository
+	testCaseRepo  repository.TestCaseRepository
+	checklistRepo repository.ChecklistRepository
+	history       HistoryService
+	tx            repository.Transactor
 }
 
-func NewTestPlanService(repo repository.TestPlanRepository) TestPlanService {
-	return &testPlanService{repo: repo}
+func NewTestPlanService(repo repository.TestPlanRepository, testCaseRepo repository.TestCaseRepository, checklistRepo repository.ChecklistRepository, history HistoryService, tx repository.Transactor) TestPlanService {
+	return &testPlanService{
+		repo:          repo,
+		testCaseRepo:  testCaseRepo,
+		checklistRepo: checklistRepo,
+		history:       history,
+		tx:            tx,
+	}
 }
 
 func (s *testPlanService) CreateTestPlan(ctx context.Context, plan *domain.TestPlan) error {
@@ -22,7 +32,13 @@ func (s *testPlanService) CreateTestPlan(ctx context.Context, plan *domain.TestP
 	plan.CreatedAt = time.Now()
 	plan.UpdatedAt = time.Now()
 
-	return s.repo.Create(ctx, plan)
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, plan); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestPlan, plan.ID, domain.HistoryActionCreated, nil, plan)
+	})
 }
 
 func (s *testPlanService) GetTestPlan(ctx context.Context, id uuid.UUID) (*domain.TestPlan, error) {
@@ -30,18 +46,90 @@ func (s *testPlanService) GetTestPlan(ctx context.Context, id uuid.UUID) (*domai
 }
 
 func (s *testPlanService) UpdateTestPlan(ctx context.Context, plan *domain.TestPlan) error {
+	before, err := s.repo.GetByID(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	if before.Version != plan.Version {
+		return domain.VersionConflict("test plan", before)
+	}
+
 	plan.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, plan)
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, plan); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestPlan, plan.ID, domain.HistoryActionUpdated, before, plan)
+	})
+	return withCurrent(err, "test plan", func() (interface{}, error) {
+		return s.repo.GetByID(ctx, plan.ID)
+	})
+}
+
+// DeleteTestPlan moves the test plan to the trash.
+func (s *testPlanService) DeleteTestPlan(ctx context.Context, id uuid.UUID) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, domain.ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestPlan, id, domain.HistoryActionDeleted, nil)
+	})
 }
 
 func (s *testPlanService) ListTestPlans(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestPlan, int64, error) {
 	return s.repo.List(ctx, projectID, page, size)
 }
 
+// AddTestCaseToPlan links a test case of the plan's project to the plan. Test
+// cases of other projects and test cases in the trash are not found.
 func (s *testPlanService) AddTestCaseToPlan(ctx context.Context, planID, testCaseID uuid.UUID) error {
-	return s.repo.AddTestCase(ctx, planID, testCaseID)
+	plan, err := s.repo.GetByID(ctx, planID)
+	if err != nil {
+		return err
+	}
+	testCase, err := s.testCaseRepo.GetByID(ctx, testCaseID)
+	if err != nil {
+		return err
+	}
+	if testCase.ProjectID != plan.ProjectID {
+		return domain.NotFound("test case")
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddTestCase(ctx, planID, testCaseID); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestPlan, planID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"test_cases": {New: testCaseID},
+		})
+	})
 }
 
+// AddChecklistToPlan links a checklist of the plan's project to the plan.
+// Checklists of other projects and checklists in the trash are not found.
 func (s *testPlanService) AddChecklistToPlan(ctx context.Context, planID, checklistID uuid.UUID) error {
-	return s.repo.AddChecklist(ctx, planID, checklistID)
+	plan, err := s.repo.GetByID(ctx, planID)
+	if err != nil {
+		return err
+	}
+	checklist, err := s.checklistRepo.GetByID(ctx, checklistID)
+	if err != nil {
+		return err
+	}
+	if checklist.ProjectID != plan.ProjectID {
+		return domain.NotFound("checklist")
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.AddChecklist(ctx, planID, checklistID); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestPlan, planID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+			"checklists": {New: checklistID},
+		})
+	})
 }
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type testRunService struct {
+	repo         repository.TestRunRepository
+	testPlanRepo repository.TestPlanRepository
+	testCaseRepo repository.TestCaseRepository
+	history      HistoryService
+	tx           repository.Transactor
+}
+
+var errTestRunCompleted = domain.Conflict("test_run_completed", "test run is already completed")
+
+func NewTestRunService(repo repository.TestRunRepository, testPlanRepo repository.TestPlanRepository, testCaseRepo repository.TestCaseRepository, history HistoryService, tx repository.Transactor) TestRunService {
+	return &testRunService{
+		repo:         repo,
+		testPlanRepo: testPlanRepo,
+		testCaseRepo: testCaseRepo,
+		history:      history,
+		tx:           tx,
+	}
+}
+
+func (s *testRunService) StartTestRun(ctx context.Context, testRun *domain.TestRun) error {
+	plan, err := s.testPlanRepo.GetByID(ctx, testRun.TestPlanID)
+	if err != nil {
+		return err
+	}
+
+	testRun.ID = uuid.New()
+	testRun.StartedAt = time.Now()
+	testRun.CompletedAt = nil
+	testRun.Results = nil
+
+	// Snapshot every test case and checklist item of the plan as pending results
+	for _, testCase := range plan.TestCases {
+		testCaseID := testCase.ID
+		testRun.Results = append(testRun.Results, domain.TestResult{
+			ID:         uuid.New(),
+			TestRunID:  testRun.ID,
+			TestCaseID: &testCaseID,
+			Status:     domain.TestResultStatusPending,
+		})
+	}
+	for _, checklist := range plan.Checklists {
+		for _, item := range checklist.Items {
+			itemID := item.ID
+			testRun.Results = append(testRun.Results, domain.TestResult{
+				ID:              uuid.New(),
+				TestRunID:       testRun.ID,
+				ChecklistItemID: &itemID,
+				Status:          domain.TestResultStatusPending,
+			})
+		}
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, testRun); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestRun, testRun.ID, domain.HistoryActionCreated, nil, testRun)
+	})
+}
+
+func (s *testRunService) RecordTestResult(ctx context.Context, result *domain.TestResult) error {
+	switch result.Status {
+	case domain.TestResultStatusPass, domain.TestResultStatusFail, domain.TestResultStatusBlocked, domain.TestResultStatusSkipped:
+	default:
+		return domain.Validation("invalid_result_status", "invalid result status",
+			domain.FieldError{Field: "status", Message: "must be one of pass, fail, blocked or skipped"})
+	}
+
+	if (result.TestCaseID == nil) == (result.ChecklistItemID == nil) {
+		return domain.Validation("invalid_result_target", "exactly one of test case or checklist item must be set")
+	}
+
+	testRun, err := s.repo.GetByID(ctx, result.TestRunID)
+	if err != nil {
+		return err
+	}
+	if testRun.CompletedAt != nil {
+		return errTestRunCompleted
+	}
+
+	existing, err := s.repo.GetResult(ctx, result.TestRunID, result.TestCaseID, result.ChecklistItemID)
+	if err != nil {
+		return err
+	}
+
+	before := *existing
+	existing.Status = result.Status
+	existing.Comments = result.Comments
+	existing.ExecutedBy = result.ExecutedBy
+	existing.ExecutedAt = time.Now()
+
+	changes, err := domain.Diff(&before, existing)
+	if err != nil {
+		return err
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateResult(ctx, existing); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestRun, existing.TestRunID, domain.HistoryActionUpdated,
+			domain.NestChanges("results."+existing.ID.String(), changes))
+	})
+	if err != nil {
+		return err
+	}
+
+	*result = *existing
+	return nil
+}
+
+func (s *testRunService) GetTestRun(ctx context.Context, id uuid.UUID) (*domain.TestRun, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteTestRun moves the test run to the trash.
+func (s *testRunService) DeleteTestRun(ctx context.Context, id uuid.UUID) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, domain.ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestRun, id, domain.HistoryActionDeleted, nil)
+	})
+}
+
+func (s *testRunService) ListTestRuns(ctx context.Context, testPlanID uuid.UUID, page, size int) ([]domain.TestRun, int64, error) {
+	return s.repo.List(ctx, testPlanID, page, size)
+}
+
+func (s *testRunService) CompleteTestRun(ctx context.Context, id uuid.UUID) error {
+	testRun, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if testRun.CompletedAt != nil {
+		return errTestRunCompleted
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Complete(ctx, id); err != nil {
+			return err
+		}
+
+		completed, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestRun, id, domain.HistoryActionUpdated, testRun, completed)
+	})
+}
+
+// IngestResults records a CI report as a completed test run of the plan.
+// Results are matched to test cases of the plan's project by automation
+// identifier; matched test cases missing from the plan are added to it.
+// Results of test cases in the trash are reported as unmatched.
+// Unmatched results create new automated test cases when req.AutoCreate is
+// set and are reported otherwise.
+func (s *testRunService) IngestResults(ctx context.Context, req *domain.IngestRequest) (*domain.IngestReport, error) {
+	plan, err := s.testPlanRepo.GetByID(ctx, req.TestPlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := domain.ParseAutomatedResults(req.Format, req.Data)
+	if err != nil {
+		return nil, domain.Validation("invalid_report", err.Error())
+	}
+
+	report := &domain.IngestReport{Format: req.Format, Total: len(results), Unmatched: []string{}}
+
+	keys := make([]string, 0, len(results))
+	for _, result := range results {
+		if utf8.RuneCountInString(result.Key) > domain.MaxAutomationKeyLength {
+			return nil, domain.Validation("invalid_report", fmt.Sprintf("automation key of %q is longer than %d characters", result.Name, domain.MaxAutomationKeyLength))
+		}
+		keys = append(keys, result.Key)
+	}
+
+	existing, err := s.testCaseRepo.FindAutomationIDs(ctx, plan.ProjectID, keys)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]uuid.UUID, len(existing))
+	for _, id := range existing {
+		byKey[id.Key] = id.TestCaseID
+	}
+
+	inPlan := make(map[uuid.UUID]bool, len(plan.TestCases))
+	for _, testCase := range plan.TestCases {
+		inPlan[testCase.ID] = true
+	}
+
+	now := time.Now()
+	var created []domain.TestCase
+	trashed := make(map[uuid.UUID]bool)
+	for _, result := range results {
+		if testCaseID, ok := byKey[result.Key]; ok {
+			// Test cases in the trash keep their keys but take no results
+			if !inPlan[testCaseID] && !trashed[testCaseID] {
+				if _, err := s.testCaseRepo.GetByID(ctx, testCaseID); domain.IsNotFound(err) {
+					trashed[testCaseID] = true
+				} else if err != nil {
+					return nil, err
+				}
+			}
+			if trashed[testCaseID] {
+				report.Unmatched = append(report.Unmatched, result.Key)
+				continue
+			}
+			report.Matched++
+			continue
+		}
+		if !req.AutoCreate {
+			report.Unmatched = append(report.Unmatched, result.Key)
+			continue
+		}
+
+		testCase := domain.TestCase{
+			ID:               uuid.New(),
+			ProjectID:        plan.ProjectID,
+			Title:            automatedTestTitle(result),
+			AutomationStatus: domain.AutomationStatusAutomated,
+			CreatedBy:        req.UserID,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		testCase.SetAutomationKeys([]string{result.Key})
+		if result.Suite != "" {
+			testCase.Description = "Automated test in " + result.Suite
+		}
+		created = append(created, testCase)
+		byKey[result.Key] = testCase.ID
+	}
+
+	testRun := &domain.TestRun{
+		ID:          uuid.New(),
+		TestPlanID:  plan.ID,
+		Name:        req.Name,
+		StartedBy:   req.UserID,
+		StartedAt:   now,
+		CompletedAt: &now,
+	}
+	if testRun.Name == "" {
+		testRun.Name = fmt.Sprintf("CI %s results %s", req.Format, now.Format("2006-01-02 15:04"))
+	}
+
+	// Created test cases, plan links and the run are stored together or not at all
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if len(created) > 0 {
+			if err := s.testCaseRepo.CreateBatch(ctx, created); err != nil {
+				return err
+			}
+			for i := range created {
+				if err := s.history.RecordDiff(ctx, domain.EntityTypeTestCase, created[i].ID, domain.HistoryActionCreated, nil, &created[i]); err != nil {
+					return err
+				}
+			}
+			report.TestCasesCreated = len(created)
+		}
+
+		for _, result := range results {
+			testCaseID, ok := byKey[result.Key]
+			if !ok || trashed[testCaseID] {
+				continue
+			}
+
+			if !inPlan[testCaseID] {
+				if err := s.testPlanRepo.AddTestCase(ctx, plan.ID, testCaseID); err != nil {
+					return err
+				}
+				if err := s.history.Record(ctx, domain.EntityTypeTestPlan, plan.ID, domain.HistoryActionUpdated, map[string]domain.FieldChange{
+					"test_cases": {New: testCaseID},
+				}); err != nil {
+					return err
+				}
+				inPlan[testCaseID] = true
+				report.AddedToPlan++
+			}
+
+			executedBy := req.UserID
+			testRun.Results = append(testRun.Results, domain.TestResult{
+				ID:         uuid.New(),
+				TestRunID:  testRun.ID,
+				TestCaseID: &testCaseID,
+				Status:     result.Status,
+				Comments:   result.Message,
+				ExecutedBy: &executedBy,
+				ExecutedAt: now,
+			})
+		}
+
+		if len(testRun.Results) == 0 {
+			return domain.Validation("no_matching_results", "no results match a test case automation key; enable auto_create to create them")
+		}
+
+		if err := s.repo.Create(ctx, testRun); err != nil {
+			return err
+		}
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestRun, testRun.ID, domain.HistoryActionCreated, nil, testRun)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.TestRunID = testRun.ID
+	report.Summary = domain.SummarizeResults(testRun.Results)
+	return report, nil
+}
+
+// automatedTestTitle names a test case created for an automated test.
+func automatedTestTitle(result domain.AutomatedResult) string {
+	title := result.Name
+	if runes := []rune(title); len(runes) > domain.MaxTestCaseTitleLength {
+		title = string(runes[:domain.MaxTestCaseTitleLength])
+	}
+	return title
+}
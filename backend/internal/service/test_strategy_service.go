@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/google/uuid"
+)
+
+type testStrategyService struct {
+	repo    repository.TestStrategyRepository
+	history HistoryService
+	tx      repository.Transactor
+}
+
+func NewTestStrategyService(repo repository.TestStrategyRepository, history HistoryService, tx repository.Transactor) TestStrategyService {
+	return &testStrategyService{
+		repo:    repo,
+		history: history,
+		tx:      tx,
+	}
+}
+
+func (s *testStrategyService) CreateTestStrategy(ctx context.Context, strategy *domain.TestStrategy) error {
+	strategy.ID = uuid.New()
+	strategy.CreatedAt = time.Now()
+	strategy.UpdatedAt = time.Now()
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, strategy); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestStrategy, strategy.ID, domain.HistoryActionCreated, nil, strategy)
+	})
+}
+
+func (s *testStrategyService) GetTestStrategy(ctx context.Context, id uuid.UUID) (*domain.TestStrategy, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *testStrategyService) UpdateTestStrategy(ctx context.Context, strategy *domain.TestStrategy) error {
+	before, err := s.repo.GetByID(ctx, strategy.ID)
+	if err != nil {
+		return err
+	}
+	if before.Version != strategy.Version {
+		return domain.VersionConflict("test strategy", before)
+	}
+
+	strategy.UpdatedAt = time.Now()
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, strategy); err != nil {
+			return err
+		}
+
+		return s.history.RecordDiff(ctx, domain.EntityTypeTestStrategy, strategy.ID, domain.HistoryActionUpdated, before, strategy)
+	})
+	return withCurrent(err, "test strategy", func() (interface{}, error) {
+		return s.repo.GetByID(ctx, strategy.ID)
+	})
+}
+
+// DeleteTestStrategy moves the test strategy to the trash.
+func (s *testStrategyService) DeleteTestStrategy(ctx context.Context, id uuid.UUID) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, id, domain.ActorFromContext(ctx)); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, domain.EntityTypeTestStrategy, id, domain.HistoryActionDeleted, nil)
+	})
+}
+
+func (s *testStrategyService) ListTestStrategies(ctx context.Context, projectID uuid.UUID, page, size int) ([]domain.TestStrategy, int64, error) {
+	return s.repo.List(ctx, projectID, page, size)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/AntVerkh/test-management-system/internal/repository"
+	"github.com/AntVerkh/test-management-system/pkg/storage"
+	"github.com/google/uuid"
+)
+
+var errUnsupportedTrashType = domain.Validation("unsupported_entity_type", "unsupported entity type",
+	domain.FieldError{Field: "entity_type", Message: "must be one of " + strings.Join(domain.TrashEntityTypes, ", ")})
+
+type trashService struct {
+	repo      repository.TrashRepository
+	history   HistoryService
+	storage   storage.FileStorage
+	retention time.Duration
+	tx        repository.Transactor
+}
+
+func NewTrashService(repo repository.TrashRepository, history HistoryService, fileStorage storage.FileStorage, retention time.Duration, tx repository.Transactor) TrashService {
+	return &trashService{
+		repo:      repo,
+		history:   history,
+		storage:   fileStorage,
+		retention: retention,
+		tx:        tx,
+	}
+}
+
+func (s *trashService) ListTrash(ctx context.Context, projectID uuid.UUID, entityType string, page, size int) ([]domain.TrashItem, int64, error) {
+	if entityType != "" && !isTrashEntityType(entityType) {
+		return nil, 0, errUnsupportedTrashType
+	}
+
+	items, total, err := s.repo.List(ctx, projectID, entityType, page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return items, total, nil
+}
+
+// RestoreItem takes an entity of the project out of the trash. Entities of
+// other projects are reported as not found.
+func (s *trashService) RestoreItem(ctx context.Context, projectID uuid.UUID, entityType string, id uuid.UUID) error {
+	if !isTrashEntityType(entityType) {
+		return errUnsupportedTrashType
+	}
+
+	item, err := s.repo.Get(ctx, entityType, id)
+	if err != nil {
+		return err
+	}
+	if item.ProjectID != projectID {
+		return domain.NotFound(strings.ReplaceAll(entityType, "_", " "))
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, entityType, id); err != nil {
+			return err
+		}
+
+		return s.history.Record(ctx, entityType, id, domain.HistoryActionRestored, nil)
+	})
+}
+
+// Purge removes everything deleted more than olderThan ago for good. Files of
+// purged attachments are removed once the database changes are committed; a
+// file that cannot be removed is logged rather than failing the purge.
+func (s *trashService) Purge(ctx context.Context, olderThan time.Duration) (*domain.PurgeReport, error) {
+	report, err := s.repo.Purge(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range report.FilePaths {
+		if err := s.storage.Delete(path); err != nil {
+			log.Printf("purge: removing %s: %v", path, err)
+		}
+	}
+	return report, nil
+}
+
+// Retention is how long entities stay in the trash before a purge.
+func (s *trashService) Retention() time.Duration {
+	return s.retention
+}
+
+func isTrashEntityType(entityType string) bool {
+	for _, t := range domain.TrashEntityTypes {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"errors"
 
 	"github.com/AntVerkh/test-management-system/internal/domain"
 	"github.com/AntVerkh/test-management-system/internal/repository"
@@ -10,11 +9,15 @@ import (
 )
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) UserService {
+	return &userService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
 }
 
 func (s *userService) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
@@ -28,16 +31,25 @@ func (s *userService) ListUsers(ctx context.Context) ([]domain.User, error) {
 func (s *userService) UpdateUserRole(ctx context.Context, userID uuid.UUID, role domain.UserRole) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return errors.New("user not found")
+		return err
 	}
 
 	// Validate role
 	switch role {
 	case domain.RoleAdmin, domain.RoleUser, domain.RoleGuest:
-		user.Role = role
 	default:
-		return errors.New("invalid role")
+		return domain.Validation("invalid_role", "invalid role", domain.FieldError{Field: "role", Message: "must be one of admin, user or guest"})
+	}
+
+	if user.Role == role {
+		return nil
+	}
+	user.Role = role
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
 	}
 
-	return s.userRepo.Update(ctx, user)
+	// Access tokens carry the role, so sign the user out everywhere
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
 }
@@ -0,0 +1,18 @@
+package service
+
+import "github.com/AntVerkh/test-management-system/internal/domain"
+
+// withCurrent completes a version conflict reported by a repository with the
+// stored copy of the entity, loaded by current, so the client can merge.
+// Other errors are returned unchanged.
+func withCurrent(err error, entity string, current func() (interface{}, error)) error {
+	if !domain.IsVersionConflict(err) {
+		return err
+	}
+
+	stored, getErr := current()
+	if getErr != nil {
+		return err
+	}
+	return domain.VersionConflict(entity, stored)
+}
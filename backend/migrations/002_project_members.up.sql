@@ -0,0 +1,14 @@
+-- Project members table
+CREATE TABLE project_members (
+                                 project_id UUID NOT NULL REFERENCES projects(id),
+                                 user_id UUID NOT NULL REFERENCES users(id),
+                                 role VARCHAR(20) NOT NULL,
+                                 created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                                 PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+-- Project creators become owners of their existing projects
+INSERT INTO project_members (project_id, user_id, role, created_at)
+SELECT id, created_by, 'owner', created_at FROM projects WHERE created_by IS NOT NULL;
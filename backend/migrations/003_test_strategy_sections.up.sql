@@ -0,0 +1,8 @@
+-- Named sections of a test strategy
+ALTER TABLE test_strategies
+    ADD COLUMN scope TEXT,
+    ADD COLUMN approach TEXT,
+    ADD COLUMN environments TEXT,
+    ADD COLUMN risks TEXT,
+    ADD COLUMN entry_criteria TEXT,
+    ADD COLUMN exit_criteria TEXT;
@@ -0,0 +1,9 @@
+-- Threaded replies and resolution state for comments
+ALTER TABLE comments
+    ADD COLUMN parent_id UUID REFERENCES comments(id),
+    ADD COLUMN resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN resolved_by UUID REFERENCES users(id),
+    ADD COLUMN resolved_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_comments_parent_id ON comments(parent_id);
@@ -0,0 +1,7 @@
+-- Attachments can belong to a test case or to a test result as execution evidence
+ALTER TABLE attachments
+    ALTER COLUMN test_case_id DROP NOT NULL,
+    ADD COLUMN test_result_id UUID REFERENCES test_results(id);
+
+CREATE INDEX idx_attachments_test_case_id ON attachments(test_case_id);
+CREATE INDEX idx_attachments_test_result_id ON attachments(test_result_id);
@@ -0,0 +1,4 @@
+-- Pending results have no executor yet; earlier snapshots stored the nil UUID
+UPDATE test_results
+SET executed_by = NULL
+WHERE executed_by = '00000000-0000-0000-0000-000000000000';
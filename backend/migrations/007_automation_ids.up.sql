@@ -0,0 +1,12 @@
+-- Automated tests in CI reports are matched to test cases by these
+-- identifiers; an identifier points to a single test case of the project
+CREATE TABLE test_case_automation_ids (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    test_case_id UUID NOT NULL REFERENCES test_cases(id),
+    project_id UUID NOT NULL REFERENCES projects(id),
+    key VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_test_case_automation_ids_test_case_id ON test_case_automation_ids(test_case_id);
+CREATE UNIQUE INDEX idx_test_case_automation_ids_key ON test_case_automation_ids(project_id, key);
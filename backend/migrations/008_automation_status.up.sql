@@ -0,0 +1,9 @@
+-- Test cases can be implemented by several automated tests, listed in
+-- test_case_automation_ids, and carry an automation status
+ALTER TABLE test_cases
+    ADD COLUMN automation_status VARCHAR(32) NOT NULL DEFAULT 'manual'
+        CHECK (automation_status IN ('manual', 'automated', 'to_be_automated'));
+
+UPDATE test_cases
+SET automation_status = 'automated'
+WHERE id IN (SELECT test_case_id FROM test_case_automation_ids);
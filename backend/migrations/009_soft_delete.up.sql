@@ -0,0 +1,27 @@
+-- Deleted plans, test cases, checklists, strategies and runs go to a
+-- per-project trash first and are only removed by an admin purge
+ALTER TABLE test_plans
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+ALTER TABLE test_cases
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+ALTER TABLE checklists
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+ALTER TABLE test_strategies
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+ALTER TABLE test_runs
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+CREATE INDEX idx_test_plans_deleted_at ON test_plans(deleted_at);
+CREATE INDEX idx_test_cases_deleted_at ON test_cases(deleted_at);
+CREATE INDEX idx_checklists_deleted_at ON checklists(deleted_at);
+CREATE INDEX idx_test_strategies_deleted_at ON test_strategies(deleted_at);
+CREATE INDEX idx_test_runs_deleted_at ON test_runs(deleted_at);
@@ -0,0 +1,6 @@
+-- Editable entities carry a version for optimistic concurrency: an update
+-- must name the version it was based on and fails if that is outdated
+ALTER TABLE test_plans ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE test_cases ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE checklists ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE test_strategies ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
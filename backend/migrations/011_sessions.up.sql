@@ -0,0 +1,15 @@
+-- Sessions back the refresh tokens; only hashes of the tokens are stored and
+-- the previous hash is kept to detect reuse of a rotated token
+CREATE TABLE sessions (
+                          id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                          user_id UUID NOT NULL REFERENCES users(id),
+                          refresh_token_hash VARCHAR(64) UNIQUE NOT NULL,
+                          previous_token_hash VARCHAR(64),
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                          last_used_at TIMESTAMP WITH TIME ZONE,
+                          revoked_at TIMESTAMP WITH TIME ZONE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX idx_sessions_previous_token_hash ON sessions(previous_token_hash);
@@ -0,0 +1,32 @@
+-- Service accounts are users that belong to a single project and can only
+-- authenticate with API tokens
+ALTER TABLE users ADD COLUMN is_service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE service_accounts (
+                                  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                  project_id UUID NOT NULL REFERENCES projects(id),
+                                  user_id UUID UNIQUE NOT NULL REFERENCES users(id),
+                                  name VARCHAR(255) NOT NULL,
+                                  description TEXT,
+                                  created_by UUID NOT NULL REFERENCES users(id),
+                                  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_service_accounts_project_id ON service_accounts(project_id);
+
+-- API tokens are stored as SHA-256 hashes; scopes is a JSON array
+CREATE TABLE api_tokens (
+                            id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                            user_id UUID NOT NULL REFERENCES users(id),
+                            name VARCHAR(255) NOT NULL,
+                            prefix VARCHAR(16) NOT NULL,
+                            token_hash VARCHAR(64) UNIQUE NOT NULL,
+                            scopes TEXT NOT NULL,
+                            expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                            last_used_at TIMESTAMP WITH TIME ZONE,
+                            revoked_at TIMESTAMP WITH TIME ZONE,
+                            created_by UUID NOT NULL REFERENCES users(id),
+                            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
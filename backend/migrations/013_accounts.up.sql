@@ -0,0 +1,31 @@
+-- Users must verify their email address before they can log in; existing
+-- accounts are treated as verified
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+UPDATE users SET email_verified_at = created_at;
+
+-- Single-use tokens for password resets and email verification, stored as hashes
+CREATE TABLE user_tokens (
+                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                             user_id UUID NOT NULL REFERENCES users(id),
+                             purpose VARCHAR(32) NOT NULL,
+                             token_hash VARCHAR(64) UNIQUE NOT NULL,
+                             expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                             used_at TIMESTAMP WITH TIME ZONE,
+                             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_tokens_user_id ON user_tokens(user_id);
+
+CREATE TABLE invitations (
+                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                             email VARCHAR(255) NOT NULL,
+                             role VARCHAR(20) NOT NULL,
+                             token_hash VARCHAR(64) UNIQUE NOT NULL,
+                             invited_by UUID NOT NULL REFERENCES users(id),
+                             expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                             accepted_at TIMESTAMP WITH TIME ZONE,
+                             revoked_at TIMESTAMP WITH TIME ZONE,
+                             created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invitations_email ON invitations(email);
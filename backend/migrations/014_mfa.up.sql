@@ -0,0 +1,29 @@
+-- TOTP enrollments, one per user; the secret is only in effect once confirmed
+CREATE TABLE mfa_enrollments (
+                                 user_id UUID PRIMARY KEY REFERENCES users(id),
+                                 secret VARCHAR(64) NOT NULL,
+                                 confirmed_at TIMESTAMP WITH TIME ZONE,
+                                 last_used_step BIGINT NOT NULL DEFAULT 0,
+                                 failed_attempts INTEGER NOT NULL DEFAULT 0,
+                                 locked_until TIMESTAMP WITH TIME ZONE,
+                                 created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE mfa_recovery_codes (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    user_id UUID NOT NULL REFERENCES users(id),
+                                    code_hash VARCHAR(64) NOT NULL,
+                                    used_at TIMESTAMP WITH TIME ZONE,
+                                    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+-- Roles that must use a second factor; roles without a row do not
+CREATE TABLE mfa_policies (
+                              role VARCHAR(20) PRIMARY KEY,
+                              required BOOLEAN NOT NULL DEFAULT FALSE,
+                              updated_by UUID REFERENCES users(id),
+                              updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,10 @@
+-- Single sign-on attempts between the redirect to the identity provider and
+-- its callback; each is used once
+CREATE TABLE oidc_auth_requests (
+                                    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                    state_hash VARCHAR(64) UNIQUE NOT NULL,
+                                    nonce VARCHAR(255) NOT NULL,
+                                    code_verifier VARCHAR(255) NOT NULL,
+                                    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                                    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -10,57 +10,113 @@ import (
 )
 
 type JWTService interface {
-	GenerateToken(user *domain.User) (string, error)
-	ValidateToken(token string) (*domain.User, error)
+	// GenerateToken issues an access token for the user's session and returns it with its expiry.
+	GenerateToken(user *domain.User, sessionID uuid.UUID) (string, time.Time, error)
+	// ValidateToken returns the user and session the access token was issued for.
+	ValidateToken(token string) (*domain.User, uuid.UUID, error)
+	// GenerateChallenge issues a short-lived token showing the user passed the
+	// password step of a login that still needs a second factor.
+	GenerateChallenge(userID uuid.UUID) (string, error)
+	// ValidateChallenge returns the user a challenge token was issued for.
+	ValidateChallenge(token string) (uuid.UUID, error)
 }
 
+const (
+	// ChallengeTTL is how long a user has to enter the second factor after the password
+	ChallengeTTL = 5 * time.Minute
+
+	// challengeAudience keeps challenge and access tokens from being used for one another
+	challengeAudience = "mfa_challenge"
+)
+
 type jwtService struct {
 	secret string
+	ttl    time.Duration
 }
 
 type Claims struct {
-	UserID uuid.UUID       `json:"user_id"`
-	Email  string          `json:"email"`
-	Role   domain.UserRole `json:"role"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Email     string          `json:"email"`
+	Role      domain.UserRole `json:"role"`
+	SessionID uuid.UUID       `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(secret string) JWTService {
-	return &jwtService{secret: secret}
+// NewJWTService creates a service signing access tokens that are valid for ttl.
+func NewJWTService(secret string, ttl time.Duration) JWTService {
+	return &jwtService{secret: secret, ttl: ttl}
 }
 
-func (s *jwtService) GenerateToken(user *domain.User) (string, error) {
+func (s *jwtService) GenerateToken(user *domain.User, sessionID uuid.UUID) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
 	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   user.ID.String(),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secret))
+	signed, err := token.SignedString([]byte(s.secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
 }
 
-func (s *jwtService) ValidateToken(tokenString string) (*domain.User, error) {
+func (s *jwtService) ValidateToken(tokenString string) (*domain.User, uuid.UUID, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(s.secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
-		return nil, err
+		return nil, uuid.Nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// Access tokens have no audience, so this refuses challenge tokens
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && len(claims.Audience) == 0 {
 		return &domain.User{
 			ID:    claims.UserID,
 			Email: claims.Email,
 			Role:  claims.Role,
-		}, nil
+		}, claims.SessionID, nil
+	}
+
+	return nil, uuid.Nil, errors.New("invalid token")
+}
+
+func (s *jwtService) GenerateChallenge(userID uuid.UUID) (string, error) {
+	now := time.Now()
+	claims := &jwt.RegisteredClaims{
+		Subject:   userID.String(),
+		Audience:  jwt.ClaimStrings{challengeAudience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ChallengeTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.secret))
+}
+
+func (s *jwtService) ValidateChallenge(tokenString string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(s.secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(challengeAudience))
+
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || !token.Valid {
+		return uuid.Nil, errors.New("invalid token")
 	}
 
-	return nil, errors.New("invalid token")
+	return uuid.Parse(claims.Subject)
 }
@@ -2,12 +2,13 @@ package database
 
 import (
 	"github.com/AntVerkh/test-management-system/internal/domain"
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func NewPostgresDB(dsn string) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
@@ -15,20 +16,98 @@ func NewPostgresDB(dsn string) (*gorm.DB, error) {
 	return db, nil
 }
 
+// backfill brings existing rows in line with a schema change.
+type backfill func(tx *gorm.DB) error
+
+// Migrate updates the schema. Data that has to change along with it is only
+// backfilled when AutoMigrate makes the change, i.e. once, on upgrade.
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := prepare(db); err != nil {
+		return err
+	}
+	backfills := pendingBackfills(db)
+
+	err := db.AutoMigrate(
 		&domain.User{},
 		&domain.Project{},
+		&domain.ProjectMember{},
 		&domain.TestPlan{},
 		&domain.TestStrategy{},
 		&domain.Checklist{},
 		&domain.ChecklistItem{},
 		&domain.TestCase{},
 		&domain.TestStep{},
+		&domain.TestCaseAutomationID{},
 		&domain.TestRun{},
 		&domain.TestResult{},
 		&domain.Attachment{},
 		&domain.Comment{},
 		&domain.History{},
+		&domain.Session{},
+		&domain.ServiceAccount{},
+		&domain.APIToken{},
+		&domain.UserToken{},
+		&domain.Invitation{},
+		&domain.MFAEnrollment{},
+		&domain.MFARecoveryCode{},
+		&domain.MFAPolicy{},
+		&domain.OIDCAuthRequest{},
 	)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, fill := range backfills {
+			if err := fill(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// prepare fixes existing rows that would keep AutoMigrate from adding a
+// constraint.
+func prepare(db *gorm.DB) error {
+	migrator := db.Migrator()
+
+	// Pending results used to store the nil UUID as their executor, which the
+	// foreign key to users rejects
+	if migrator.HasTable(&domain.TestResult{}) && !migrator.HasConstraint(&domain.TestResult{}, "Executor") {
+		return db.Exec("UPDATE test_results SET executed_by = NULL WHERE executed_by = ?", uuid.Nil).Error
+	}
+	return nil
+}
+
+// pendingBackfills returns the backfills for schema changes AutoMigrate is
+// about to make. A database without the projects table is new and has nothing
+// to backfill.
+func pendingBackfills(db *gorm.DB) []backfill {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&domain.Project{}) {
+		return nil
+	}
+
+	var backfills []backfill
+
+	// Before project memberships, every user could reach every project; the
+	// creators become owners so the projects are not left without members
+	if !migrator.HasTable(&domain.ProjectMember{}) {
+		backfills = append(backfills, func(tx *gorm.DB) error {
+			return tx.Exec(`INSERT INTO project_members (project_id, user_id, role, created_at)
+				SELECT id, created_by, ?, created_at FROM projects WHERE created_by IS NOT NULL
+				ON CONFLICT DO NOTHING`, domain.ProjectRoleOwner).Error
+		})
+	}
+
+	// Accounts from before email verification are treated as verified, or
+	// nobody could log in after the upgrade
+	if !migrator.HasColumn(&domain.User{}, "EmailVerifiedAt") {
+		backfills = append(backfills, func(tx *gorm.DB) error {
+			return tx.Exec("UPDATE users SET email_verified_at = COALESCE(created_at, CURRENT_TIMESTAMP) WHERE email_verified_at IS NULL").Error
+		})
+	}
+
+	return backfills
 }
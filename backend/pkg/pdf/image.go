@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// MaxImagePixels caps the size of images. Decoding needs memory for every
+// pixel, and a small file can declare huge dimensions.
+const MaxImagePixels = 25 * 1000 * 1000
+
+type Image struct {
+	name       string
+	width      int
+	height     int
+	colorSpace string
+	filter     string
+	data       []byte
+}
+
+// Size returns the pixel dimensions of the image.
+func (img *Image) Size() (int, int) {
+	return img.width, img.height
+}
+
+// AddImage registers a JPEG, PNG or GIF image with the document. JPEG data is
+// embedded as is; other formats are decoded and stored as compressed RGB,
+// with transparency flattened onto white. Images of more than MaxImagePixels
+// pixels are rejected.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	}
+
+	img := &Image{
+		name:   fmt.Sprintf("Im%d", len(d.images)+1),
+		width:  config.Width,
+		height: config.Height,
+	}
+
+	if format == "jpeg" && config.ColorModel != color.CMYKModel {
+		img.filter = "DCTDecode"
+		img.colorSpace = "DeviceRGB"
+		if config.ColorModel == color.GrayModel {
+			img.colorSpace = "DeviceGray"
+		}
+		img.data = data
+	} else {
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported image: %w", err)
+		}
+		img.width, img.height, img.data, err = flatten(decoded)
+		if err != nil {
+			return nil, err
+		}
+		img.filter = "FlateDecode"
+		img.colorSpace = "DeviceRGB"
+	}
+
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// flatten converts any image to deflated 8-bit RGB samples.
+func flatten(src image.Image) (int, int, []byte, error) {
+	bounds := src.Bounds()
+	raw := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := src.At(x, y).RGBA()
+			// Colors are alpha-premultiplied, so blending onto white only
+			// needs the uncovered part added back
+			white := 0xffff - a
+			raw = append(raw, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	data, err := deflate(raw)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return bounds.Dx(), bounds.Dy(), data, nil
+}
@@ -0,0 +1,291 @@
+// Package pdf writes simple PDF documents using only the standard library.
+//
+// It supports the two built-in Helvetica faces with WinAnsi encoding, filled
+// and stroked rectangles, lines, raster images and internal page links, which
+// is all the report exporters need. Coordinates are in points with the origin
+// in the top-left corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+func (f Font) resourceName() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+type Color struct {
+	R, G, B uint8
+}
+
+// Hex builds a color from a 0xRRGGBB value.
+func Hex(value uint32) Color {
+	return Color{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value)}
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+type Document struct {
+	Title   string
+	Author  string
+	Created time.Time
+
+	pages  []*Page
+	images []*Image
+}
+
+func New(title string) *Document {
+	return &Document{Title: title, Created: time.Now()}
+}
+
+// AddPage appends a blank page to the document.
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// InsertPage inserts a blank page at the given zero-based position.
+func (d *Document) InsertPage(index int) *Page {
+	if index >= len(d.pages) {
+		return d.AddPage()
+	}
+	page := &Page{doc: d}
+	d.pages = append(d.pages[:index], append([]*Page{page}, d.pages[index:]...)...)
+	return page
+}
+
+// Pages returns the pages in document order.
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// PageNumber returns the one-based position of the page, or 0 when the page
+// does not belong to the document.
+func (d *Document) PageNumber(page *Page) int {
+	for i, p := range d.pages {
+		if p == page {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+type link struct {
+	x, y, w, h float64
+	target     *Page
+}
+
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+	links   []link
+}
+
+// SetFillColor sets the color used for text and filled shapes.
+func (p *Page) SetFillColor(c Color) {
+	fmt.Fprintf(&p.content, "%s rg\n", c.operands())
+}
+
+// SetStrokeColor sets the color used for lines and rectangle borders.
+func (p *Page) SetStrokeColor(c Color) {
+	fmt.Fprintf(&p.content, "%s RG\n", c.operands())
+}
+
+func (p *Page) SetLineWidth(width float64) {
+	fmt.Fprintf(&p.content, "%.2f w\n", width)
+}
+
+// Text draws a single line of text with its baseline at y.
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font.resourceName(), size, x, PageHeight-y, escape(encode(text)))
+}
+
+// Rect draws a rectangle whose top-left corner is at x, y.
+func (p *Page) Rect(x, y, w, h float64, fill, stroke bool) {
+	op := "S"
+	switch {
+	case fill && stroke:
+		op = "B"
+	case fill:
+		op = "f"
+	}
+	fmt.Fprintf(&p.content, "%.2f %.2f %.2f %.2f re %s\n", x, PageHeight-y-h, w, h, op)
+}
+
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "%.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Image draws img scaled to w×h with its top-left corner at x, y.
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", w, h, x, PageHeight-y-h, img.name)
+}
+
+// Link makes the area a clickable link to the target page.
+func (p *Page) Link(x, y, w, h float64, target *Page) {
+	p.links = append(p.links, link{x: x, y: y, w: w, h: h, target: target})
+}
+
+// Bytes serializes the document.
+func (d *Document) Bytes() ([]byte, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		fontObj    = 3
+		boldObj    = 4
+		infoObj    = 5
+		firstImage = 6
+	)
+	firstPage := firstImage + len(d.images)
+	pageObj := func(i int) int { return firstPage + 2*i }
+
+	var buf bytes.Buffer
+	offsets := []int{0}
+	begin := func(num int) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", num)
+	}
+	end := func() {
+		buf.WriteString("endobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	begin(catalogObj)
+	fmt.Fprintf(&buf, "<< /Type /Catalog /Pages %d 0 R >>\n", pagesObj)
+	end()
+
+	begin(pagesObj)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj(i))
+	}
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	end()
+
+	begin(fontObj)
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\n")
+	end()
+
+	begin(boldObj)
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\n")
+	end()
+
+	begin(infoObj)
+	fmt.Fprintf(&buf, "<< /Title (%s) /Author (%s) /Producer (Test Management System) /CreationDate (D:%s) >>\n",
+		escape(encode(d.Title)), escape(encode(d.Author)), d.Created.UTC().Format("20060102150405Z"))
+	end()
+
+	for i, img := range d.images {
+		begin(firstImage + i)
+		fmt.Fprintf(&buf, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /%s /Length %d >>\nstream\n",
+			img.width, img.height, img.colorSpace, img.filter, len(img.data))
+		buf.Write(img.data)
+		buf.WriteString("\nendstream\n")
+		end()
+	}
+
+	xobjects := make([]string, len(d.images))
+	for i, img := range d.images {
+		xobjects[i] = fmt.Sprintf("/%s %d 0 R", img.name, firstImage+i)
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject << %s >> >>",
+		fontObj, boldObj, strings.Join(xobjects, " "))
+
+	for i, page := range d.pages {
+		annots := make([]string, 0, len(page.links))
+		for _, l := range page.links {
+			target := d.PageNumber(l.target)
+			if target == 0 {
+				continue
+			}
+			annots = append(annots, fmt.Sprintf("<< /Type /Annot /Subtype /Link /Border [0 0 0] /Rect [%.2f %.2f %.2f %.2f] /Dest [%d 0 R /XYZ null null null] >>",
+				l.x, PageHeight-l.y-l.h, l.x+l.w, PageHeight-l.y, pageObj(target-1)))
+		}
+
+		begin(pageObj(i))
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R",
+			pagesObj, PageWidth, PageHeight, resources, pageObj(i)+1)
+		if len(annots) > 0 {
+			fmt.Fprintf(&buf, " /Annots [%s]", strings.Join(annots, " "))
+		}
+		buf.WriteString(" >>\n")
+		end()
+
+		content, err := deflate(page.content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		begin(pageObj(i) + 1)
+		fmt.Fprintf(&buf, "<< /Length %d /Filter /FlateDecode >>\nstream\n", len(content))
+		buf.Write(content)
+		buf.WriteString("\nendstream\n")
+		end()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n", len(offsets))
+	buf.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets), catalogObj, infoObj, xref)
+
+	return buf.Bytes(), nil
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// escape quotes the special characters of a PDF literal string.
+func escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Glyph widths of the printable ASCII range (32–126) in thousandths of the
+// font size, taken from the Adobe core font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// winAnsi maps the characters of the 0x80–0x9F block of Windows-1252.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts UTF-8 text to WinAnsi bytes. Characters the standard fonts
+// cannot show are replaced with '?'.
+func encode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			sb.WriteString("    ")
+		case r < 0x20 || r == 0x7F:
+			sb.WriteByte(' ')
+		case r < 0x80:
+			sb.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			sb.WriteByte(byte(r))
+		case r == '→':
+			sb.WriteString("->")
+		default:
+			if b, ok := winAnsi[r]; ok {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('?')
+			}
+		}
+	}
+	return sb.String()
+}
+
+func glyphWidth(font Font, c byte) int {
+	if c >= 32 && c <= 126 {
+		if font == HelveticaBold {
+			return helveticaBoldWidths[c-32]
+		}
+		return helveticaWidths[c-32]
+	}
+	switch c {
+	case 0x85, 0x97:
+		return 1000
+	case 0x95:
+		return 350
+	case 0xA0:
+		return 278
+	}
+	return 556
+}
+
+// TextWidth returns the width of text in points.
+func TextWidth(font Font, size float64, text string) float64 {
+	encoded := encode(text)
+	total := 0
+	for i := 0; i < len(encoded); i++ {
+		total += glyphWidth(font, encoded[i])
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText breaks text into lines no wider than maxWidth. Explicit line breaks
+// are kept and words longer than a line are split.
+func WrapText(font Font, size float64, text string, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.FieldsFunc(paragraph, unicode.IsSpace)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(font, size, candidate) <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			for TextWidth(font, size, word) > maxWidth {
+				head, tail := splitToWidth(font, size, word, maxWidth)
+				lines = append(lines, head)
+				word = tail
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitToWidth cuts the longest prefix of word that fits into maxWidth,
+// always keeping at least one character so wrapping makes progress.
+func splitToWidth(font Font, size float64, word string, maxWidth float64) (string, string) {
+	runes := []rune(word)
+	cut := 1
+	for cut < len(runes) && TextWidth(font, size, string(runes[:cut+1])) <= maxWidth {
+		cut++
+	}
+	return string(runes[:cut]), string(runes[cut:])
+}
+
+// Truncate shortens text with an ellipsis so that it fits into maxWidth.
+func Truncate(font Font, size float64, text string, maxWidth float64) string {
+	if TextWidth(font, size, text) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(font, size, string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -8,7 +8,7 @@ import Register from './pages/Register';
 import Dashboard from './pages/Dashboard';
 import TestPlans from './pages/TestPlans';
 import TestCases from './pages/TestCases';
-import { authAPI } from './services/api'; // Fixed import path
+import { authAPI, storeTokens, clearTokens } from './services/api'; // Fixed import path
 
 const queryClient = new QueryClient();
 
@@ -24,7 +24,7 @@ function App() {
                     setUser(response.data);
                 })
                 .catch(() => {
-                    localStorage.removeItem('token');
+                    clearTokens();
                 })
                 .finally(() => {
                     setLoading(false);
@@ -34,14 +34,18 @@ function App() {
         }
     }, []);
 
-    const login = (userData, token) => {
-        localStorage.setItem('token', token);
+    const login = (userData, tokens) => {
+        storeTokens(tokens);
         setUser(userData);
     };
 
     const logout = () => {
-        localStorage.removeItem('token');
-        setUser(null);
+        authAPI.logout()
+            .catch(() => {})
+            .finally(() => {
+                clearTokens();
+                setUser(null);
+            });
     };
 
     if (loading) {
@@ -58,6 +62,11 @@ function App() {
                             path="/login"
                             element={!user ? <Login onLogin={login} /> : <Navigate to="/dashboard" />}
                         />
+                        {/* The identity provider returns here after single sign-on */}
+                        <Route
+                            path="/oidc/callback"
+                            element={!user ? <Login onLogin={login} /> : <Navigate to="/dashboard" />}
+                        />
                         <Route
                             path="/register"
                             element={!user ? <Register onRegister={login} /> : <Navigate to="/dashboard" />}
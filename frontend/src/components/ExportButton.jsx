@@ -1,5 +1,5 @@
-import React, { useState } from 'react';
-import { downloadExport } from '../services/api';
+import React, { useEffect, useState } from 'react';
+import { downloadExport, fetchExportFormats } from '../services/export';
 
 const ExportButton = ({
                           entityType,
@@ -10,13 +10,36 @@ const ExportButton = ({
                       }) => {
     const [isExporting, setIsExporting] = useState(false);
     const [showOptions, setShowOptions] = useState(false);
+    const [formats, setFormats] = useState([]);
+    const [selectedFormat, setSelectedFormat] = useState('markdown');
+
+    useEffect(() => {
+        if (!showOptions || formats.length > 0) {
+            return;
+        }
+        fetchExportFormats()
+            .then((available) => {
+                const supported = available.filter(
+                    (format) => !format.entity_types || format.entity_types.includes(entityType)
+                );
+                setFormats(supported);
+                if (supported.length > 0 && !supported.some((format) => format.format === selectedFormat)) {
+                    setSelectedFormat(supported[0].format);
+                }
+            })
+            .catch((error) => console.error('Failed to load export formats:', error));
+    }, [showOptions, formats.length, entityType, selectedFormat]);
 
     const handleExport = async (includeHistory = false, includeComments = false) => {
         setIsExporting(true);
         setShowOptions(false);
 
+        const format = formats.find((item) => item.format === selectedFormat);
+
         try {
             await downloadExport(entityType, entityId, {
+                format: selectedFormat,
+                extension: format?.extension,
                 includeHistory,
                 includeComments
             });
@@ -78,6 +101,23 @@ const ExportButton = ({
                             Export Options for {entityName}
                         </div>
 
+                        {formats.length > 0 && (
+                            <div className="px-4 py-2 border-b">
+                                <label className="block text-xs text-gray-500 mb-1">Format</label>
+                                <select
+                                    value={selectedFormat}
+                                    onChange={(e) => setSelectedFormat(e.target.value)}
+                                    className="w-full border rounded-md px-2 py-1 text-sm"
+                                >
+                                    {formats.map((format) => (
+                                        <option key={format.format} value={format.format}>
+                                            {format.name} (.{format.extension})
+                                        </option>
+                                    ))}
+                                </select>
+                            </div>
+                        )}
+
                         <button
                             onClick={() => handleExport(false, false)}
                             className="block w-full text-left px-4 py-2 text-sm text-gray-700 hover:bg-gray-100"
@@ -1,21 +1,159 @@
-import React from 'react';
+import React, { useEffect, useRef, useState } from 'react';
 import { useForm } from 'react-hook-form';
+import { useLocation, useNavigate } from 'react-router-dom';
 import { authAPI } from '../services/api'; // Fixed import path
 import { toast } from 'react-hot-toast';
 
 const Login = ({ onLogin }) => {
-    const { register, handleSubmit, formState: { errors, isSubmitting } } = useForm();
+    const { register, handleSubmit, reset, formState: { errors, isSubmitting } } = useForm();
+    // Set when the password or single sign-on was accepted but a second factor is still needed
+    const [mfa, setMfa] = useState(null);
+    const [setup, setSetup] = useState(null);
+    const [recoveryCodes, setRecoveryCodes] = useState(null);
+    const [session, setSession] = useState(null);
+    const [sso, setSso] = useState(null);
+    const location = useLocation();
+    const navigate = useNavigate();
+    // React runs effects twice in development; a callback can only be redeemed once
+    const ssoHandled = useRef(false);
+
+    const finishLogin = (data) => {
+        onLogin(data.user, data);
+        toast.success('Login successful!');
+    };
+
+    // Either signs in or continues with the second factor, for passwords and single sign-on alike
+    const handleLoginResult = async (result) => {
+        if (!result.mfa_required) {
+            finishLogin(result);
+            return;
+        }
+
+        setMfa(result);
+        reset({ code: '' });
+        if (result.mfa_enrollment_required) {
+            const enrollment = await authAPI.enrollMfaLogin(result.mfa_token);
+            setSetup(enrollment.data);
+        }
+    };
+
+    useEffect(() => {
+        authAPI.getSsoInfo()
+            .then((response) => setSso(response.data))
+            .catch(() => setSso(null));
+    }, []);
+
+    useEffect(() => {
+        if (location.pathname !== '/oidc/callback' || ssoHandled.current) {
+            return;
+        }
+        ssoHandled.current = true;
+
+        // Drop the code from the address bar; once the user is set the route leaves for the dashboard
+        const params = new URLSearchParams(location.search);
+        navigate(location.pathname, { replace: true });
+        if (params.get('error')) {
+            toast.error(params.get('error_description') || 'Single sign-on was cancelled.');
+            return;
+        }
+
+        authAPI.completeSso(params.get('code'), params.get('state'))
+            .then((response) => handleLoginResult(response.data))
+            .catch((error) => toast.error(error.response?.data?.error || 'Single sign-on failed.'));
+    }, [location]); // eslint-disable-line react-hooks/exhaustive-deps
 
     const onSubmit = async (data) => {
         try {
             const response = await authAPI.login(data);
-            onLogin(response.data.user, response.data.token);
-            toast.success('Login successful!');
+            await handleLoginResult(response.data);
         } catch (error) {
             toast.error('Login failed. Please check your credentials.');
         }
     };
 
+    const onSso = async () => {
+        try {
+            const response = await authAPI.startSso();
+            window.location.href = response.data.authorization_url;
+        } catch (error) {
+            toast.error('Single sign-on is not available right now.');
+        }
+    };
+
+    const onSubmitCode = async ({ code }) => {
+        try {
+            if (setup) {
+                const response = await authAPI.confirmMfaLogin(mfa.mfa_token, code);
+                // Recovery codes are only shown once, so keep them on screen until acknowledged
+                setRecoveryCodes(response.data.recovery_codes);
+                setSession(response.data);
+                return;
+            }
+
+            const response = await authAPI.completeMfaLogin(mfa.mfa_token, code);
+            finishLogin(response.data);
+        } catch (error) {
+            toast.error(error.response?.data?.error || 'Invalid authentication code.');
+            // The MFA token expired, so the password has to be entered again
+            if (error.response?.status === 401) {
+                setMfa(null);
+                setSetup(null);
+            }
+        }
+    };
+
+    if (recoveryCodes) {
+        return (
+            <div className="auth-page">
+                <div className="auth-container">
+                    <h1>Save your recovery codes</h1>
+                    <p>Each code signs you in once if you lose access to your authenticator app. They are not shown again.</p>
+                    <ul className="recovery-codes">
+                        {recoveryCodes.map((code) => <li key={code}><code>{code}</code></li>)}
+                    </ul>
+                    <button type="button" className="btn btn-primary" onClick={() => finishLogin(session)}>
+                        I have saved them
+                    </button>
+                </div>
+            </div>
+        );
+    }
+
+    if (mfa) {
+        return (
+            <div className="auth-page">
+                <div className="auth-container">
+                    <h1>Two-factor authentication</h1>
+                    {setup ? (
+                        <>
+                            <p>Your role requires two-factor authentication. Add this account to your authenticator app, then enter the code it shows.</p>
+                            <p><a href={setup.provisioning_uri}>Open in authenticator app</a></p>
+                            <p>Or enter the key manually: <code>{setup.secret}</code></p>
+                        </>
+                    ) : (
+                        <p>Enter the code from your authenticator app, or one of your recovery codes.</p>
+                    )}
+                    <form onSubmit={handleSubmit(onSubmitCode)} className="auth-form">
+                        <div className="form-group">
+                            <label>Code</label>
+                            <input
+                                type="text"
+                                autoComplete="one-time-code"
+                                autoFocus
+                                {...register('code', { required: 'Code is required' })}
+                            />
+                            {errors.code && <span className="error">{errors.code.message}</span>}
+                        </div>
+
+                        <button type="submit" disabled={isSubmitting} className="btn btn-primary">
+                            {isSubmitting ? 'Verifying...' : 'Verify'}
+                        </button>
+                    </form>
+                </div>
+            </div>
+        );
+    }
+
     return (
         <div className="auth-page">
             <div className="auth-container">
@@ -44,6 +182,12 @@ const Login = ({ onLogin }) => {
                     </button>
                 </form>
 
+                {sso?.enabled && (
+                    <button type="button" className="btn btn-secondary" onClick={onSso}>
+                        Sign in with {sso.provider_name}
+                    </button>
+                )}
+
                 <p>
                     Don't have an account? <a href="/register">Register here</a>
                 </p>
@@ -52,4 +196,4 @@ const Login = ({ onLogin }) => {
     );
 };
 
-export default Login;
\ No newline at end of file
+export default Login;
@@ -9,7 +9,7 @@ const Register = ({ onRegister }) => {
     const onSubmit = async (data) => {
         try {
             const response = await authAPI.register(data);
-            toast.success('Registration successful! Please login.');
+            toast.success('Registration successful! Check your email to verify your address, then login.');
             // Redirect to login after successful registration
             setTimeout(() => {
                 window.location.href = '/login';
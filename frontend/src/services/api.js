@@ -18,12 +18,49 @@ api.interceptors.request.use((config) => {
     return config;
 });
 
-// Response interceptor to handle errors
+export const storeTokens = ({ token, refresh_token: refreshToken }) => {
+    localStorage.setItem('token', token);
+    localStorage.setItem('refreshToken', refreshToken);
+};
+
+export const clearTokens = () => {
+    localStorage.removeItem('token');
+    localStorage.removeItem('refreshToken');
+};
+
+// Refresh tokens rotate on every use, so concurrent 401s share one refresh
+let refreshing = null;
+
+const refreshTokens = () => {
+    if (!refreshing) {
+        const refreshToken = localStorage.getItem('refreshToken');
+        refreshing = (refreshToken
+            ? axios.post(`${API_BASE_URL}/auth/refresh`, { refresh_token: refreshToken })
+                .then((response) => storeTokens(response.data))
+            : Promise.reject(new Error('no refresh token'))
+        ).finally(() => {
+            refreshing = null;
+        });
+    }
+    return refreshing;
+};
+
+// Response interceptor to refresh expired access tokens and handle errors
 api.interceptors.response.use(
     (response) => response,
-    (error) => {
-        if (error.response?.status === 401) {
-            localStorage.removeItem('token');
+    async (error) => {
+        const { config, response } = error;
+        if (response?.status === 401 && config && !config._retried && !config.url.startsWith('/auth/')) {
+            config._retried = true;
+            try {
+                await refreshTokens();
+                return api(config);
+            } catch {
+                // fall through to signing out
+            }
+        }
+        if (response?.status === 401 && !config?.url.startsWith('/auth/login')) {
+            clearTokens();
             window.location.href = '/login';
         }
         return Promise.reject(error);
@@ -32,9 +69,52 @@ api.interceptors.response.use(
 
 // Auth API
 export const authAPI = {
+    // Answers with mfa_required and an mfa_token instead of tokens when a second factor is needed
     login: (credentials) => api.post('/auth/login', credentials),
+    completeMfaLogin: (mfaToken, code) => api.post('/auth/login/mfa', { mfa_token: mfaToken, code }),
+    enrollMfaLogin: (mfaToken) => api.post('/auth/login/mfa/enroll', { mfa_token: mfaToken }),
+    confirmMfaLogin: (mfaToken, code) => api.post('/auth/login/mfa/confirm', { mfa_token: mfaToken, code }),
+    // Single sign-on: send the browser to authorization_url, then pass the code and state it returns with
+    getSsoInfo: () => api.get('/auth/oidc'),
+    startSso: () => api.post('/auth/oidc/authorize'),
+    completeSso: (code, state) => api.post('/auth/oidc/callback', { code, state }),
     register: (userData) => api.post('/auth/register', userData),
+    logout: () => api.post('/auth/logout'),
     getProfile: () => api.get('/profile'),
+    verifyEmail: (token) => api.post('/auth/verify-email', { token }),
+    resendVerification: (email) => api.post('/auth/resend-verification', { email }),
+    forgotPassword: (email) => api.post('/auth/forgot-password', { email }),
+    resetPassword: (token, password) => api.post('/auth/reset-password', { token, password }),
+    acceptInvitation: (token, password) => api.post('/auth/invitations/accept', { token, password }),
+    // Changing the password ends every session, so the new tokens replace the stored ones
+    changePassword: (currentPassword, newPassword) => api
+        .post('/auth/change-password', { current_password: currentPassword, new_password: newPassword })
+        .then((response) => {
+            storeTokens(response.data);
+            return response;
+        }),
+};
+
+// Two-factor authentication API
+export const mfaAPI = {
+    getStatus: () => api.get('/auth/mfa'),
+    enroll: () => api.post('/auth/mfa/enroll'),
+    confirm: (code) => api.post('/auth/mfa/confirm', { code }),
+    regenerateRecoveryCodes: (code) => api.post('/auth/mfa/recovery-codes', { code }),
+    disable: (code) => api.post('/auth/mfa/disable', { code }),
+    // Admin
+    getUserStatus: (userId) => api.get(`/admin/users/${userId}/mfa`),
+    resetUser: (userId) => api.delete(`/admin/users/${userId}/mfa`),
+    getPolicies: () => api.get('/admin/mfa-policies'),
+    setPolicy: (role, required) => api.put(`/admin/mfa-policies/${role}`, { required }),
+};
+
+// Invitations API (admin)
+export const invitationsAPI = {
+    getAll: () => api.get('/admin/invitations'),
+    // data: { email, role }
+    create: (data) => api.post('/admin/invitations', data),
+    revoke: (id) => api.delete(`/admin/invitations/${id}`),
 };
 
 // Test Plans API
@@ -43,25 +123,159 @@ export const testPlansAPI = {
     getById: (id) => api.get(`/test-plans/${id}`),
     create: (data) => api.post('/test-plans', data),
     update: (id, data) => api.put(`/test-plans/${id}`, data),
+    remove: (id) => api.delete(`/test-plans/${id}`),
     addTestCase: (planId, testCaseId) => api.post(`/test-plans/${planId}/test-cases`, { test_case_id: testCaseId }),
+    addChecklist: (planId, checklistId) => api.post(`/test-plans/${planId}/checklists`, { checklist_id: checklistId }),
+    // options: { format, name, autoCreate }; the format is detected from the report when omitted
+    ingestResults: (planId, file, { format, name, autoCreate } = {}) => {
+        const formData = new FormData();
+        formData.append('file', file);
+        if (format) formData.append('format', format);
+        if (name) formData.append('name', name);
+        if (autoCreate) formData.append('auto_create', 'true');
+        return api.post(`/test-plans/${planId}/automated-results`, formData, {
+            headers: { 'Content-Type': 'multipart/form-data' },
+        });
+    },
 };
 
 // Test Cases API
 export const testCasesAPI = {
-    getAll: (projectId) => api.get(`/test-cases?project_id=${projectId}`),
+    // filters: { automation_status, automation_id }
+    getAll: (projectId, filters = {}) => api.get('/test-cases', { params: { project_id: projectId, ...filters } }),
     getById: (id) => api.get(`/test-cases/${id}`),
+    getAutomationCoverage: (projectId) => api.get(`/projects/${projectId}/automation-coverage`),
     create: (data) => api.post('/test-cases', data),
     update: (id, data) => api.put(`/test-cases/${id}`, data),
+    remove: (id) => api.delete(`/test-cases/${id}`),
+    addStep: (id, data) => api.post(`/test-cases/${id}/steps`, data),
+    updateStep: (id, stepId, data) => api.put(`/test-cases/${id}/steps/${stepId}`, data),
+    removeStep: (id, stepId) => api.delete(`/test-cases/${id}/steps/${stepId}`),
+    moveStep: (id, stepId, order) => api.post(`/test-cases/${id}/steps/${stepId}/move`, { order }),
+    // options: { format, mapping, dryRun }; the format is guessed from the file name when omitted
+    import: (projectId, file, { format, mapping, dryRun } = {}) => {
+        const formData = new FormData();
+        formData.append('file', file);
+        if (format) formData.append('format', format);
+        if (mapping) formData.append('mapping', JSON.stringify(mapping));
+        if (dryRun) formData.append('dry_run', 'true');
+        return api.post(`/projects/${projectId}/test-cases/import`, formData, {
+            headers: { 'Content-Type': 'multipart/form-data' },
+        });
+    },
+};
+
+// Checklists API
+export const checklistsAPI = {
+    getAll: (projectId) => api.get(`/checklists?project_id=${projectId}`),
+    getById: (id) => api.get(`/checklists/${id}`),
+    create: (data) => api.post('/checklists', data),
+    update: (id, data) => api.put(`/checklists/${id}`, data),
+    remove: (id) => api.delete(`/checklists/${id}`),
+    addItem: (id, data) => api.post(`/checklists/${id}/items`, data),
+    updateItem: (id, itemId, data) => api.put(`/checklists/${id}/items/${itemId}`, data),
+    removeItem: (id, itemId) => api.delete(`/checklists/${id}/items/${itemId}`),
+    reorderItems: (id, itemIds) => api.post(`/checklists/${id}/items/reorder`, { item_ids: itemIds }),
+};
+
+// Test Strategies API
+export const testStrategiesAPI = {
+    getAll: (projectId) => api.get(`/test-strategies?project_id=${projectId}`),
+    getById: (id) => api.get(`/test-strategies/${id}`),
+    create: (data) => api.post('/test-strategies', data),
+    update: (id, data) => api.put(`/test-strategies/${id}`, data),
+    remove: (id) => api.delete(`/test-strategies/${id}`),
+};
+
+// Test Runs API
+export const testRunsAPI = {
+    getAll: (testPlanId) => api.get(`/test-runs?test_plan_id=${testPlanId}`),
+    getById: (id) => api.get(`/test-runs/${id}`),
+    start: (data) => api.post('/test-runs', data),
+    recordResult: (id, data) => api.post(`/test-runs/${id}/results`, data),
+    complete: (id) => api.post(`/test-runs/${id}/complete`),
+    remove: (id) => api.delete(`/test-runs/${id}`),
+};
+
+// Trash API
+export const trashAPI = {
+    getAll: (projectId, entityType = '', page = 1) => api.get(`/projects/${projectId}/trash`, { params: { entity_type: entityType || undefined, page } }),
+    restore: (projectId, entityType, entityId) => api.post(`/projects/${projectId}/trash/${entityType}/${entityId}/restore`),
+    purge: (olderThanDays) => api.post('/admin/trash/purge', null, { params: { older_than_days: olderThanDays } }),
+};
+
+// API tokens API; the token value is only returned by create
+export const tokensAPI = {
+    getAll: () => api.get('/tokens'),
+    // data: { name, scopes, expires_in_days }
+    create: (data) => api.post('/tokens', data),
+    revoke: (id) => api.delete(`/tokens/${id}`),
+};
+
+// Service Accounts API
+export const serviceAccountsAPI = {
+    getAll: (projectId) => api.get(`/projects/${projectId}/service-accounts`),
+    create: (projectId, data) => api.post(`/projects/${projectId}/service-accounts`, data),
+    remove: (projectId, accountId) => api.delete(`/projects/${projectId}/service-accounts/${accountId}`),
+    getTokens: (projectId, accountId) => api.get(`/projects/${projectId}/service-accounts/${accountId}/tokens`),
+    createToken: (projectId, accountId, data) => api.post(`/projects/${projectId}/service-accounts/${accountId}/tokens`, data),
+    revokeToken: (projectId, accountId, tokenId) => api.delete(`/projects/${projectId}/service-accounts/${accountId}/tokens/${tokenId}`),
 };
 
 // Projects API
 export const projectsAPI = {
     getAll: () => api.get('/projects'),
+    getById: (id) => api.get(`/projects/${id}`),
     create: (data) => api.post('/projects', data),
+    update: (id, data) => api.put(`/projects/${id}`, data),
+    getMembers: (id) => api.get(`/projects/${id}/members`),
+    addMember: (id, data) => api.post(`/projects/${id}/members`, data),
+    updateMember: (id, userId, data) => api.put(`/projects/${id}/members/${userId}`, data),
+    removeMember: (id, userId) => api.delete(`/projects/${id}/members/${userId}`),
+};
+
+// History API
+export const historyAPI = {
+    getTestPlanHistory: (id, page = 1) => api.get(`/test-plans/${id}/history?page=${page}`),
+    getTestCaseHistory: (id, page = 1) => api.get(`/test-cases/${id}/history?page=${page}`),
+    getChecklistHistory: (id, page = 1) => api.get(`/checklists/${id}/history?page=${page}`),
+    getTestStrategyHistory: (id, page = 1) => api.get(`/test-strategies/${id}/history?page=${page}`),
+    getTestRunHistory: (id, page = 1) => api.get(`/test-runs/${id}/history?page=${page}`),
+};
+
+// Comments API
+export const commentsAPI = {
+    getAll: (entityType, entityId, page = 1) => api.get(`/comments?entity_type=${entityType}&entity_id=${entityId}&page=${page}`),
+    create: (data) => api.post('/comments', data),
+    update: (id, data) => api.put(`/comments/${id}`, data),
+    remove: (id) => api.delete(`/comments/${id}`),
+    resolve: (id) => api.post(`/comments/${id}/resolve`),
+    reopen: (id) => api.post(`/comments/${id}/reopen`),
+};
+
+// Attachments API
+const uploadFile = (url, file) => {
+    const formData = new FormData();
+    formData.append('file', file);
+    return api.post(url, formData, {
+        headers: { 'Content-Type': 'multipart/form-data' },
+    });
+};
+
+export const attachmentsAPI = {
+    uploadToTestCase: (testCaseId, file) => uploadFile(`/test-cases/${testCaseId}/attachments`, file),
+    uploadToTestResult: (resultId, file) => uploadFile(`/test-results/${resultId}/attachments`, file),
+    download: (id) => api.get(`/attachments/${id}/download`, { responseType: 'blob' }),
+    remove: (id) => api.delete(`/attachments/${id}`),
 };
 
 // Export API
 export const exportAPI = {
+    getFormats: () => api.get('/export/formats'),
+    exportProjectTestCases: (projectId, format = 'csv') =>
+        api.get(`/projects/${projectId}/test-cases/export?format=${format}`, {
+            responseType: 'blob'
+        }),
     exportTestPlan: (id, options = {}) =>
         api.get(`/test-plans/${id}/export?include_history=${options.includeHistory || false}&include_comments=${options.includeComments || false}`, {
             responseType: 'blob'
@@ -1,12 +1,12 @@
-import api from './api';
+import api, { exportAPI } from './api';
 
 export const downloadExport = async (entityType, entityId, options = {}) => {
-    const { includeHistory = false, includeComments = false } = options;
+    const { format = 'markdown', includeHistory = false, includeComments = false } = options;
 
     const response = await api.post('/export', {
         entity_type: entityType,
         entity_id: entityId,
-        format: 'markdown',
+        format,
         include_history: includeHistory,
         include_comments: includeComments
     }, {
@@ -14,16 +14,17 @@ export const downloadExport = async (entityType, entityId, options = {}) => {
     });
 
     // Create blob and download
-    const blob = new Blob([response.data], { type: 'text/markdown' });
+    const contentType = response.headers['content-type'] || 'application/octet-stream';
+    const blob = new Blob([response.data], { type: contentType });
     const url = window.URL.createObjectURL(blob);
     const link = document.createElement('a');
     link.href = url;
 
     // Extract filename from content-disposition header
     const contentDisposition = response.headers['content-disposition'];
-    let filename = `${entityType}_${entityId}.md`;
+    let filename = `${entityType}_${entityId}.${options.extension || 'md'}`;
     if (contentDisposition) {
-        const filenameMatch = contentDisposition.match(/filename="(.+)"/);
+        const filenameMatch = contentDisposition.match(/filename="?([^";]+)"?/);
         if (filenameMatch) {
             filename = filenameMatch[1];
         }
@@ -36,6 +37,11 @@ export const downloadExport = async (entityType, entityId, options = {}) => {
     window.URL.revokeObjectURL(url);
 };
 
+export const fetchExportFormats = async () => {
+    const response = await exportAPI.getFormats();
+    return response.data;
+};
+
 // Convenience functions for specific entity types
 export const exportTestPlan = (planId, options) =>
     downloadExport('test_plan', planId, options);